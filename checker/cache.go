@@ -0,0 +1,43 @@
+package checker
+
+import (
+	"sync"
+	"time"
+)
+
+// simpleCache is an in-memory, TTL-expiring Cache.
+type simpleCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	result  DomainResult
+	expires time.Time
+}
+
+// MakeSimpleCache returns a Cache that holds each result for ttl before
+// expiring it, so a burst of calls for the same domain only probes it once.
+func MakeSimpleCache(ttl time.Duration) Cache {
+	return &simpleCache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+// Get implements Cache.
+func (c *simpleCache) Get(key string) (DomainResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return DomainResult{}, false
+	}
+	return entry.result, true
+}
+
+// Put implements Cache.
+func (c *simpleCache) Put(key string, result DomainResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = cacheEntry{result: result, expires: time.Now().Add(c.ttl)}
+}
@@ -0,0 +1,239 @@
+// Package checker performs the per-domain security checks Validator and
+// mtastscache consult: does a domain's mail get delivered over STARTTLS
+// with a certificate that actually validates (opportunistic), and, for
+// domains that publish one, does their MTA-STS policy (RFC 8461) fetch and
+// its enforced hostnames match the servers actually receiving mail.
+package checker
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DomainStatus classifies the outcome of checking a domain, ordered from
+// best (Success) to worst (DomainBadHostnameFailure); a 0-6 status->label
+// mapping downstream keys off this ordering, so existing values keep their
+// number when new ones are appended.
+type DomainStatus int
+
+// Domain check outcomes, ordered least to most severe.
+const (
+	// Success means every MX host checked supports STARTTLS with a
+	// certificate that validates, and, if published, the MTA-STS policy
+	// fetched and covers them.
+	Success DomainStatus = iota
+	// DomainFailedTesting means the domain hasn't completed enrollment
+	// testing yet.
+	DomainFailedTesting
+	// DomainCouldNotConnect means no MX host could be reached at all.
+	DomainCouldNotConnect
+	// DomainSTARTTLSFailure means an MX host was reached but doesn't
+	// support (or rejects) STARTTLS.
+	DomainSTARTTLSFailure
+	// DomainDANEFailure means DANE TLSA validation failed against the
+	// certificate an MX host presented; see the dane package.
+	DomainDANEFailure
+	// DomainCertificateFailure means an MX host's certificate failed
+	// WebPKI validation (expired, or signed by an untrusted issuer).
+	DomainCertificateFailure
+	// DomainBadHostnameFailure is checker's worst-case status: an MX
+	// host's certificate doesn't cover the hostname it was reached at.
+	DomainBadHostnameFailure
+)
+
+// MTASTSResult is the parsed outcome of fetching a domain's MTA-STS policy.
+type MTASTSResult struct {
+	// Policy is the raw policy file fetched over HTTPS.
+	Policy string
+	// MaxAge is the policy's "max_age" directive, in seconds.
+	MaxAge int
+	// MXs is the policy's enforced "mx" host patterns.
+	MXs []string
+}
+
+// DomainResult is the outcome of checking a single domain.
+type DomainResult struct {
+	Domain  string
+	Status  DomainStatus
+	Message string
+	// MTASTSResult is non-nil when a policy was successfully fetched,
+	// whether or not the check as a whole passed.
+	MTASTSResult *MTASTSResult
+}
+
+// Class satisfies raven's Interface interface, so a DomainResult can be
+// attached to a Sentry report.
+func (d DomainResult) Class() string {
+	return "extra"
+}
+
+// Cache lets a Checker skip re-probing a domain it has already checked
+// recently. MakeSimpleCache returns the in-memory implementation Validator
+// uses by default.
+type Cache interface {
+	Get(key string) (DomainResult, bool)
+	Put(key string, result DomainResult)
+}
+
+// Checker performs security checks against domains, optionally caching
+// results to avoid re-probing the same domain on every call.
+type Checker struct {
+	Cache Cache
+}
+
+// CheckDomain checks name's mail delivery security. If mxs is empty, name
+// is assumed to publish an MTA-STS policy and the check fetches and
+// validates that instead of probing a caller-supplied MX list directly.
+func (c Checker) CheckDomain(name string, mxs []string) DomainResult {
+	key := name
+	if len(mxs) > 0 {
+		key = name + "|" + strings.Join(mxs, ",")
+	}
+	if c.Cache != nil {
+		if result, ok := c.Cache.Get(key); ok {
+			return result
+		}
+	}
+	var result DomainResult
+	if len(mxs) == 0 {
+		result = checkMTASTS(name)
+	} else {
+		result = checkOpportunistic(name, mxs)
+	}
+	if c.Cache != nil {
+		c.Cache.Put(key, result)
+	}
+	return result
+}
+
+// checkMTASTS fetches name's MTA-STS policy over HTTPS and validates it
+// against the MX hosts it enforces, per RFC 8461 §3-4.
+func checkMTASTS(name string) DomainResult {
+	resp, err := http.Get("https://mta-sts." + name + "/.well-known/mta-sts.txt")
+	if err != nil {
+		return DomainResult{Domain: name, Status: DomainCouldNotConnect,
+			Message: fmt.Sprintf("mta-sts: fetching policy for %s: %v", name, err)}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return DomainResult{Domain: name, Status: DomainCouldNotConnect,
+			Message: fmt.Sprintf("mta-sts: policy fetch for %s returned status %d", name, resp.StatusCode)}
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return DomainResult{Domain: name, Status: DomainCouldNotConnect,
+			Message: fmt.Sprintf("mta-sts: reading policy for %s: %v", name, err)}
+	}
+
+	maxAge, mxPatterns := parsePolicy(string(body))
+	result := checkOpportunistic(name, mxPatterns)
+	result.MTASTSResult = &MTASTSResult{Policy: string(body), MaxAge: maxAge, MXs: mxPatterns}
+	return result
+}
+
+// parsePolicy extracts the "max_age" and "mx" directives from a raw MTA-STS
+// policy file, per RFC 8461 §3.2.
+func parsePolicy(policy string) (maxAge int, mxs []string) {
+	for _, line := range strings.Split(policy, "\n") {
+		line = strings.TrimSpace(line)
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch key {
+		case "max_age":
+			if n, err := strconv.Atoi(value); err == nil {
+				maxAge = n
+			}
+		case "mx":
+			mxs = append(mxs, value)
+		}
+	}
+	return maxAge, mxs
+}
+
+// checkOpportunistic probes every host in mxs for STARTTLS support and a
+// WebPKI-valid certificate, stopping at the first failure.
+func checkOpportunistic(name string, mxs []string) DomainResult {
+	if len(mxs) == 0 {
+		return DomainResult{Domain: name, Status: DomainCouldNotConnect,
+			Message: fmt.Sprintf("no MX hosts to check for %s", name)}
+	}
+	for _, mx := range mxs {
+		if status, msg := probeSTARTTLS(mx); status != Success {
+			return DomainResult{Domain: name, Status: status, Message: msg}
+		}
+	}
+	return DomainResult{Domain: name, Status: Success}
+}
+
+// starttlsDialTimeout bounds the STARTTLS handshake probeSTARTTLS performs.
+const starttlsDialTimeout = 10 * time.Second
+
+// probeSTARTTLS connects to mxHost on the SMTP port, negotiates STARTTLS,
+// and validates the presented certificate against the WebPKI, reporting
+// which DomainStatus (if any) the attempt failed with.
+func probeSTARTTLS(mxHost string) (DomainStatus, string) {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(mxHost, "25"), starttlsDialTimeout)
+	if err != nil {
+		return DomainCouldNotConnect, fmt.Sprintf("could not connect to %s: %v", mxHost, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(starttlsDialTimeout))
+
+	text := textproto.NewConn(conn)
+	if _, _, err := text.ReadResponse(220); err != nil {
+		return DomainCouldNotConnect, fmt.Sprintf("reading banner from %s: %v", mxHost, err)
+	}
+	id, err := text.Cmd("EHLO starttls-validator")
+	if err != nil {
+		return DomainCouldNotConnect, fmt.Sprintf("sending EHLO to %s: %v", mxHost, err)
+	}
+	text.StartResponse(id)
+	_, _, err = text.ReadResponse(250)
+	text.EndResponse(id)
+	if err != nil {
+		return DomainSTARTTLSFailure, fmt.Sprintf("EHLO rejected by %s: %v", mxHost, err)
+	}
+
+	id, err = text.Cmd("STARTTLS")
+	if err != nil {
+		return DomainCouldNotConnect, fmt.Sprintf("sending STARTTLS to %s: %v", mxHost, err)
+	}
+	text.StartResponse(id)
+	_, _, err = text.ReadResponse(220)
+	text.EndResponse(id)
+	if err != nil {
+		return DomainSTARTTLSFailure, fmt.Sprintf("starttls not supported by %s: %v", mxHost, err)
+	}
+
+	tlsConn := tls.Client(conn, &tls.Config{ServerName: mxHost})
+	if err := tlsConn.Handshake(); err != nil {
+		return classifyTLSError(mxHost, err)
+	}
+	return Success, ""
+}
+
+// classifyTLSError maps a failed TLS handshake's error onto a DomainStatus,
+// by inspecting the message Go's x509 verifier produces, so callers (and
+// validator.classifyFailure, which matches on these same substrings) can
+// tell a hostname mismatch apart from an expired or untrusted certificate.
+func classifyTLSError(mxHost string, err error) (DomainStatus, string) {
+	msg := err.Error()
+	switch {
+	case strings.Contains(msg, "not valid for"), strings.Contains(msg, "hostname"):
+		return DomainBadHostnameFailure, fmt.Sprintf("certificate hostname mismatch for %s: %v", mxHost, err)
+	case strings.Contains(msg, "expired"):
+		return DomainCertificateFailure, fmt.Sprintf("certificate expired for %s: %v", mxHost, err)
+	default:
+		return DomainCertificateFailure, fmt.Sprintf("certificate for %s is not trusted: %v", mxHost, err)
+	}
+}
@@ -0,0 +1,38 @@
+package dane
+
+import "fmt"
+
+// CheckMX validates mxHost's presented certificate chain against its TLSA
+// record set. It reports ok=false, err=nil when the host has no
+// DNSSEC-authenticated TLSA records to check against (DANE simply doesn't
+// apply), distinct from an ok=false, err!=nil validation failure.
+func CheckMX(mxHost string) (ok bool, err error) {
+	records, err := LookupRecords(mxHost)
+	if err != nil {
+		return false, err
+	}
+	return CheckRecords(mxHost, records)
+}
+
+// CheckRecords is CheckMX for a TLSA record set the caller already looked
+// up, so a caller that needs the records for some other reason (e.g.
+// deciding whether DANE applies at all) doesn't have to pay for a second
+// DNS round trip to check them.
+func CheckRecords(mxHost string, records []Record) (ok bool, err error) {
+	if len(records) == 0 {
+		return false, nil
+	}
+
+	chain, err := FetchChain(mxHost)
+	if err != nil {
+		return false, fmt.Errorf("dane: fetching certificate chain for %s: %v", mxHost, err)
+	}
+	if len(chain) == 0 {
+		return false, fmt.Errorf("dane: %s presented no certificates", mxHost)
+	}
+
+	if _, matched := MatchesAny(records, chain[0], chain); !matched {
+		return false, fmt.Errorf("dane: no TLSA record for %s matched the presented certificate chain", mxHost)
+	}
+	return true, nil
+}
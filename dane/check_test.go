@@ -0,0 +1,13 @@
+package dane
+
+import "testing"
+
+func TestCheckRecordsNoRecordsIsNotAnError(t *testing.T) {
+	ok, err := CheckRecords("mx.example.com", nil)
+	if ok {
+		t.Fatal("CheckRecords: ok = true with no TLSA records")
+	}
+	if err != nil {
+		t.Fatalf("CheckRecords: err = %v, want nil (no records means DANE doesn't apply, not a failure)", err)
+	}
+}
@@ -0,0 +1,27 @@
+package dane
+
+import (
+	"encoding/hex"
+	"net"
+
+	"github.com/miekg/dns"
+)
+
+// resolverAddr returns the "host:port" of the system's configured DNS
+// resolver. DANE requires a DNSSEC-validating resolver (RFC 7672 §2.2.1);
+// operators are expected to point this machine's resolv.conf at one, the
+// same assumption a validating stub resolver makes.
+func resolverAddr() string {
+	const fallback = "127.0.0.1:53"
+	conf, err := dns.ClientConfigFromFile("/etc/resolv.conf")
+	if err != nil || len(conf.Servers) == 0 {
+		return fallback
+	}
+	return net.JoinHostPort(conf.Servers[0], conf.Port)
+}
+
+// hexDecode decodes a TLSA record's hex-encoded certificate association
+// data field.
+func hexDecode(s string) ([]byte, error) {
+	return hex.DecodeString(s)
+}
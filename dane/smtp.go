@@ -0,0 +1,63 @@
+package dane
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/textproto"
+	"time"
+)
+
+// dialTimeout bounds the STARTTLS handshake used to fetch an MX host's
+// certificate chain for TLSA comparison.
+const dialTimeout = 10 * time.Second
+
+// FetchChain connects to mxHost on the SMTP port, negotiates STARTTLS, and
+// returns the certificate chain the server presents. DANE validates this
+// chain against TLSA records instead of the WebPKI, so the handshake here
+// skips normal certificate verification (InsecureSkipVerify) and leaves
+// trust decisions to MatchesAny.
+func FetchChain(mxHost string) ([]*x509.Certificate, error) {
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(mxHost, "25"), dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("dane: dialing %s: %v", mxHost, err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(dialTimeout))
+
+	text := textproto.NewConn(conn)
+	if _, _, err := text.ReadResponse(220); err != nil {
+		return nil, fmt.Errorf("dane: reading banner from %s: %v", mxHost, err)
+	}
+	id, err := text.Cmd("EHLO starttls-validator")
+	if err != nil {
+		return nil, fmt.Errorf("dane: sending EHLO to %s: %v", mxHost, err)
+	}
+	text.StartResponse(id)
+	_, _, err = text.ReadResponse(250)
+	text.EndResponse(id)
+	if err != nil {
+		return nil, fmt.Errorf("dane: EHLO rejected by %s: %v", mxHost, err)
+	}
+
+	id, err = text.Cmd("STARTTLS")
+	if err != nil {
+		return nil, fmt.Errorf("dane: sending STARTTLS to %s: %v", mxHost, err)
+	}
+	text.StartResponse(id)
+	_, _, err = text.ReadResponse(220)
+	text.EndResponse(id)
+	if err != nil {
+		return nil, fmt.Errorf("dane: STARTTLS rejected by %s: %v", mxHost, err)
+	}
+
+	tlsConn := tls.Client(conn, &tls.Config{
+		ServerName:         mxHost,
+		InsecureSkipVerify: true,
+	})
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, fmt.Errorf("dane: TLS handshake with %s: %v", mxHost, err)
+	}
+	return tlsConn.ConnectionState().PeerCertificates, nil
+}
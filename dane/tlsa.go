@@ -0,0 +1,154 @@
+// Package dane implements DANE TLSA certificate validation (RFC 7672) for
+// SMTP MX hosts, as an alternative or complement to MTA-STS: a mail server
+// can publish TLSA records under `_25._tcp.<mxhost>` instead of (or as well
+// as) an MTA-STS policy, with a DNSSEC-validating resolver making those
+// records trustworthy.
+package dane
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"fmt"
+
+	"github.com/miekg/dns"
+)
+
+// Usage is the TLSA record's certificate usage field (RFC 6698 §2.1.1).
+// DANE-EE and DANE-TA are the usages relevant to opportunistic SMTP
+// (RFC 7672 §3.1); PKIX-TA/PKIX-EE are accepted but rarely published.
+type Usage uint8
+
+// Certificate usages defined by RFC 6698 §2.1.1.
+const (
+	UsagePKIXTA Usage = 0
+	UsagePKIXEE Usage = 1
+	UsageDANETA Usage = 2
+	UsageDANEEE Usage = 3
+)
+
+// Selector is the TLSA record's selector field (RFC 6698 §2.1.2).
+type Selector uint8
+
+// Selectors defined by RFC 6698 §2.1.2.
+const (
+	SelectorFullCertificate      Selector = 0
+	SelectorSubjectPublicKeyInfo Selector = 1
+)
+
+// MatchingType is the TLSA record's matching type field (RFC 6698 §2.1.3).
+type MatchingType uint8
+
+// Matching types defined by RFC 6698 §2.1.3.
+const (
+	MatchingTypeFull   MatchingType = 0
+	MatchingTypeSHA256 MatchingType = 1
+	MatchingTypeSHA512 MatchingType = 2
+)
+
+// Record is one parsed TLSA resource record.
+type Record struct {
+	Usage        Usage
+	Selector     Selector
+	MatchingType MatchingType
+	// Data is the certificate association data: the full certificate or
+	// SPKI, or its SHA-256/SHA-512 digest, depending on MatchingType.
+	Data []byte
+}
+
+// LookupRecords fetches and parses the TLSA record set for mxHost's SMTP
+// service, i.e. `_25._tcp.<mxHost>`. The AD (Authentic Data) bit in the
+// response must be set for records to be treated as DNSSEC-validated;
+// RFC 7672 §3.1 requires callers to treat an unauthenticated reply the same
+// as no TLSA records found, not as a validation failure, so this returns
+// (nil, nil) rather than an error in that case.
+func LookupRecords(mxHost string) ([]Record, error) {
+	name := dns.Fqdn("_25._tcp." + mxHost)
+	m := new(dns.Msg)
+	m.SetQuestion(name, dns.TypeTLSA)
+	m.SetEdns0(4096, true)
+
+	in, err := dns.Exchange(m, resolverAddr())
+	if err != nil {
+		return nil, fmt.Errorf("dane: TLSA lookup for %s: %v", mxHost, err)
+	}
+	if !in.AuthenticatedData {
+		return nil, nil
+	}
+	var records []Record
+	for _, rr := range in.Answer {
+		tlsa, ok := rr.(*dns.TLSA)
+		if !ok {
+			continue
+		}
+		data, err := hexDecode(tlsa.Certificate)
+		if err != nil {
+			continue
+		}
+		records = append(records, Record{
+			Usage:        Usage(tlsa.Usage),
+			Selector:     Selector(tlsa.Selector),
+			MatchingType: MatchingType(tlsa.MatchingType),
+			Data:         data,
+		})
+	}
+	return records, nil
+}
+
+// MatchesAny reports whether cert (and, for usages that require it, its
+// issuer chain) satisfies any record in records, per RFC 6698 §2.2.
+func MatchesAny(records []Record, cert *x509.Certificate, chain []*x509.Certificate) (Record, bool) {
+	for _, r := range records {
+		for _, candidate := range certsForUsage(r.Usage, cert, chain) {
+			if matches(r, candidate) {
+				return r, true
+			}
+		}
+	}
+	return Record{}, false
+}
+
+// certsForUsage returns the certificates a record's usage says should be
+// checked against its association data: just the leaf for DANE-EE/PKIX-EE,
+// or the whole chain for DANE-TA/PKIX-TA (any CA in the chain may match).
+func certsForUsage(usage Usage, leaf *x509.Certificate, chain []*x509.Certificate) []*x509.Certificate {
+	switch usage {
+	case UsageDANEEE, UsagePKIXEE:
+		return []*x509.Certificate{leaf}
+	case UsageDANETA, UsagePKIXTA:
+		return chain
+	default:
+		return nil
+	}
+}
+
+// matches reports whether cert's selected data matches r's association
+// data under r's matching type.
+func matches(r Record, cert *x509.Certificate) bool {
+	if cert == nil {
+		return false
+	}
+	var selected []byte
+	switch r.Selector {
+	case SelectorFullCertificate:
+		selected = cert.Raw
+	case SelectorSubjectPublicKeyInfo:
+		selected = cert.RawSubjectPublicKeyInfo
+	default:
+		return false
+	}
+
+	switch r.MatchingType {
+	case MatchingTypeFull:
+		return bytes.Equal(selected, r.Data)
+	case MatchingTypeSHA256:
+		sum := sha256.Sum256(selected)
+		return bytes.Equal(sum[:], r.Data)
+	case MatchingTypeSHA512:
+		sum := sha512.Sum512(selected)
+		return bytes.Equal(sum[:], r.Data)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,124 @@
+// Package db implements persistent storage for starttls-backend, including
+// the concrete validator.DomainPolicyStore Validator checks domains against.
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/EFForg/starttls-backend/models"
+	"github.com/EFForg/starttls-backend/validator"
+)
+
+// policyState is the on-disk shape of a PolicyStore.
+type policyState struct {
+	Domains   map[string]models.Domain            `json:"domains"`
+	Overrides map[string]validator.PolicyOverride `json:"overrides"`
+}
+
+// PolicyStore is a validator.DomainPolicyStore backed by a JSON file: the
+// enrolled domains it serves up for validation, and any PolicyOverrides
+// recorded against them, the same on-disk-JSON-plus-mutex approach
+// mtastscache.Cache uses for its own state.
+type PolicyStore struct {
+	path string
+
+	mu    sync.Mutex
+	state policyState
+}
+
+// Load reads a PolicyStore from the JSON file at path. A missing file is
+// not an error; it starts empty and is created on first write.
+func Load(path string) (*PolicyStore, error) {
+	s := &PolicyStore{path: path, state: policyState{
+		Domains:   make(map[string]models.Domain),
+		Overrides: make(map[string]validator.PolicyOverride),
+	}}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return s, nil
+		}
+		return nil, fmt.Errorf("db: reading %s: %v", path, err)
+	}
+	if err := json.Unmarshal(data, &s.state); err != nil {
+		return nil, fmt.Errorf("db: parsing %s: %v", path, err)
+	}
+	if s.state.Domains == nil {
+		s.state.Domains = make(map[string]models.Domain)
+	}
+	if s.state.Overrides == nil {
+		s.state.Overrides = make(map[string]validator.PolicyOverride)
+	}
+	return s, nil
+}
+
+// PutDomain adds or updates domain in the store.
+func (s *PolicyStore) PutDomain(domain models.Domain) error {
+	s.mu.Lock()
+	s.state.Domains[domain.Name] = domain
+	s.mu.Unlock()
+	return s.save()
+}
+
+// DomainsToValidate implements validator.DomainPolicyStore.
+func (s *PolicyStore) DomainsToValidate() ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.state.Domains))
+	for name := range s.state.Domains {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// GetDomain implements validator.DomainPolicyStore.
+func (s *PolicyStore) GetDomain(name string) (models.Domain, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	domain, ok := s.state.Domains[name]
+	if !ok {
+		return models.Domain{}, fmt.Errorf("db: no domain recorded for %s", name)
+	}
+	return domain, nil
+}
+
+// RecordOverride implements validator.DomainPolicyStore.
+func (s *PolicyStore) RecordOverride(domain string, override validator.PolicyOverride) error {
+	s.mu.Lock()
+	s.state.Overrides[domain] = override
+	s.mu.Unlock()
+	return s.save()
+}
+
+// GetOverride implements validator.DomainPolicyStore. A zero-value
+// PolicyOverride (zero ExpiresAt) means none is recorded for domain.
+func (s *PolicyStore) GetOverride(domain string) (validator.PolicyOverride, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state.Overrides[domain], nil
+}
+
+// save writes the store to disk as JSON, the same temp-file-then-rename
+// approach mtastscache.Cache.save uses.
+func (s *PolicyStore) save() error {
+	s.mu.Lock()
+	data, err := json.Marshal(s.state)
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("db: marshaling store: %v", err)
+	}
+	if dir := filepath.Dir(s.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("db: creating %s: %v", dir, err)
+		}
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("db: writing %s: %v", tmp, err)
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,37 @@
+// Package models defines the data types persisted across starttls-backend:
+// the domains enrolled in (or monitored by) STARTTLS Everywhere, and the
+// policies Validator checks them against.
+package models
+
+import "github.com/EFForg/starttls-backend/checker"
+
+// Domain represents a domain's enrollment and policy state.
+type Domain struct {
+	// Name is the domain name.
+	Name string
+	// MXs lists the mail exchange hostnames to check directly when MTASTS
+	// is false, for opportunistic and (if DANE is set) DANE validation.
+	MXs []string
+	// MTASTS is whether this domain publishes (and should be checked
+	// against) an MTA-STS policy, instead of checking MXs directly.
+	MTASTS bool
+	// Policy is the last-known MTA-STS policy text for this domain, used
+	// by SamePolicy to detect when the published policy has drifted.
+	Policy string
+	// DANE is whether this domain's MX hosts should additionally be
+	// validated against DANE TLSA records (RFC 7672), on top of (or
+	// instead of) MTASTS.
+	DANE bool
+	// Severity is the domain's validator.PolicySeverity, as a plain string
+	// since PolicySeverity is declared in the validator package and models
+	// can't import it without a cycle. The zero value defaults to
+	// validator.HardMandatory.
+	Severity string
+}
+
+// SamePolicy reports whether policy matches the MTA-STS policy already on
+// file for this domain, so GetDBCheck knows whether the database needs
+// updating.
+func (d Domain) SamePolicy(policy *checker.MTASTSResult) bool {
+	return policy != nil && policy.Policy == d.Policy
+}
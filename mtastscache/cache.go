@@ -0,0 +1,256 @@
+// Package mtastscache implements an on-disk cache of MTA-STS policies, modeled
+// on the way full MTA implementations cache policies per RFC 8461 §5: each
+// domain's fetched policy is kept alongside enough metadata (the TXT record's
+// "id=" token, when the policy was last fetched and last used, and when it
+// expires) that Validator can avoid re-doing DNS and HTTPS work on every
+// validation cycle.
+package mtastscache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/EFForg/starttls-backend/checker"
+)
+
+// negativeCacheBackoff is how long we avoid re-fetching a domain after a
+// TXT lookup or HTTPS fetch failure, so a single broken domain doesn't get
+// hammered on every validation cycle.
+const negativeCacheBackoff = 5 * time.Minute
+
+// Entry is the cached state of a single domain's MTA-STS policy.
+type Entry struct {
+	Domain string
+	// Policy is the raw policy file fetched over HTTPS.
+	Policy string
+	// RecordID is the "id=" token from the domain's _mta-sts TXT record,
+	// used to detect when the published policy has changed.
+	RecordID string
+	// MaxAge is the policy's "max_age" directive, used to derive ValidEnd.
+	MaxAge time.Duration
+
+	Inserted   time.Time
+	LastUpdate time.Time
+	LastUse    time.Time
+	ValidEnd   time.Time
+
+	// Backoff is set when the last TXT lookup or HTTPS fetch failed, so we
+	// negative-cache the domain instead of retrying every cycle.
+	Backoff bool
+
+	// Result is the last checker.DomainResult produced for this domain,
+	// returned as-is by GetDBCheck while the entry is still fresh.
+	Result checker.DomainResult
+}
+
+// needsRefresh reports whether this entry is stale enough that GetDBCheck
+// should re-fetch rather than trust the cached policy. The refresh interval
+// is derived from the entry's own policy max_age (half of it, as recommended
+// for MTA-STS caches), falling back to defaultInterval when no MaxAge is
+// known yet.
+func (e Entry) needsRefresh(defaultInterval time.Duration, recordID string) bool {
+	if e.Backoff {
+		return time.Since(e.LastUpdate) >= negativeCacheBackoff
+	}
+	if recordID != "" && recordID != e.RecordID {
+		return true
+	}
+	interval := defaultInterval
+	if e.MaxAge > 0 {
+		interval = e.MaxAge / 2
+	}
+	return time.Since(e.LastUpdate) >= interval
+}
+
+// approachingExpiry reports whether the entry is close enough to ValidEnd
+// that the background refresher should proactively re-fetch it.
+func (e Entry) approachingExpiry(margin time.Duration) bool {
+	if e.ValidEnd.IsZero() {
+		return false
+	}
+	return time.Now().Add(margin).After(e.ValidEnd)
+}
+
+// Cache is an in-memory store of Entry, written to disk on every Put and
+// every Evict that removes entries, so that policy state survives restarts
+// of the validator process.
+type Cache struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string]Entry
+
+	// saveMu serializes calls to save, since Put and Evict can both trigger
+	// one and they write to the same on-disk temp file.
+	saveMu sync.Mutex
+}
+
+// Load reads a Cache from the JSON file at path. A missing file is not an
+// error; it simply starts an empty cache whose file is created on the first
+// Put or Evict that writes it.
+func Load(path string) (*Cache, error) {
+	c := &Cache{path: path, entries: make(map[string]Entry)}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return nil, fmt.Errorf("mtastscache: reading %s: %v", path, err)
+	}
+	var entries map[string]Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("mtastscache: parsing %s: %v", path, err)
+	}
+	c.entries = entries
+	return c, nil
+}
+
+// Get returns the cached entry for domain, marking it used, and reports
+// whether a re-fetch is required. defaultRefreshInterval is used only for
+// domains we haven't yet cached a max_age for.
+func (c *Cache) Get(domain string, defaultRefreshInterval time.Duration) (Entry, bool, bool) {
+	recordID, _ := lookupRecordIDFn(domain)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[domain]
+	if !ok {
+		return Entry{}, false, true
+	}
+	e.LastUse = time.Now()
+	c.entries[domain] = e
+	return e, true, e.needsRefresh(defaultRefreshInterval, recordID)
+}
+
+// Put inserts or updates the cache entry for domain and persists the cache.
+// RecordID is filled in from the domain's current _mta-sts TXT record (the
+// same lookup Get performs) unless the caller already knows it's stale,
+// e.g. because the fetch that produced e.Result failed; a blank RecordID
+// would otherwise make needsRefresh treat this entry as permanently stale.
+func (c *Cache) Put(e Entry) error {
+	if e.RecordID == "" && !e.Backoff {
+		if recordID, err := lookupRecordIDFn(e.Domain); err == nil {
+			e.RecordID = recordID
+		}
+	}
+
+	now := time.Now()
+	c.mu.Lock()
+	if existing, ok := c.entries[e.Domain]; ok {
+		e.Inserted = existing.Inserted
+	} else {
+		e.Inserted = now
+	}
+	e.LastUpdate = now
+	if e.LastUse.IsZero() {
+		e.LastUse = now
+	}
+	if e.MaxAge > 0 {
+		e.ValidEnd = now.Add(e.MaxAge)
+	}
+	c.entries[e.Domain] = e
+	c.mu.Unlock()
+	return c.save()
+}
+
+// Walk calls fn for every cached entry. fn must not mutate the Cache.
+func (c *Cache) Walk(fn func(Entry)) {
+	c.mu.Lock()
+	entries := make([]Entry, 0, len(c.entries))
+	for _, e := range c.entries {
+		entries = append(entries, e)
+	}
+	c.mu.Unlock()
+	for _, e := range entries {
+		fn(e)
+	}
+}
+
+// ApproachingExpiry returns the domains whose cached policy will expire
+// within margin, for a background refresher to re-fetch proactively.
+func (c *Cache) ApproachingExpiry(margin time.Duration) []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var domains []string
+	for domain, e := range c.entries {
+		if e.approachingExpiry(margin) {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
+
+// Evict removes entries whose LastUse is older than ttl, returning the
+// number of entries removed and the result of persisting the cache
+// afterward (0, nil if nothing was removed).
+func (c *Cache) Evict(ttl time.Duration) (int, error) {
+	c.mu.Lock()
+	removed := 0
+	for domain, e := range c.entries {
+		if time.Since(e.LastUse) > ttl {
+			delete(c.entries, domain)
+			removed++
+		}
+	}
+	c.mu.Unlock()
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, c.save()
+}
+
+// save writes the cache to disk as JSON. save serializes its own writes to
+// c.path+".tmp" with saveMu, since Put and Evict can otherwise race to
+// write that same temp file and corrupt it.
+func (c *Cache) save() error {
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+
+	c.mu.Lock()
+	data, err := json.Marshal(c.entries)
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("mtastscache: marshaling cache: %v", err)
+	}
+	if dir := filepath.Dir(c.path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("mtastscache: creating %s: %v", dir, err)
+		}
+	}
+	tmp := c.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("mtastscache: writing %s: %v", tmp, err)
+	}
+	return os.Rename(tmp, c.path)
+}
+
+// lookupRecordIDFn is a var so tests can stub out the live DNS lookup.
+var lookupRecordIDFn = lookupRecordID
+
+// lookupRecordID fetches the "id=" token from a domain's _mta-sts TXT
+// record, the same record MTA-STS clients consult to detect policy changes.
+func lookupRecordID(domain string) (string, error) {
+	records, err := net.LookupTXT("_mta-sts." + domain)
+	if err != nil {
+		return "", err
+	}
+	for _, record := range records {
+		if !strings.HasPrefix(record, "v=STSv1") {
+			continue
+		}
+		for _, field := range strings.Split(record, ";") {
+			field = strings.TrimSpace(field)
+			if strings.HasPrefix(field, "id=") {
+				return strings.TrimPrefix(field, "id="), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("mtastscache: no STSv1 TXT record found for %s", domain)
+}
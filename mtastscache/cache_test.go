@@ -0,0 +1,125 @@
+package mtastscache
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/EFForg/starttls-backend/checker"
+)
+
+// withStubRecordID stubs the live DNS lookup for the duration of a test,
+// restoring the real implementation on cleanup.
+func withStubRecordID(t *testing.T, id string, err error) {
+	t.Helper()
+	orig := lookupRecordIDFn
+	lookupRecordIDFn = func(string) (string, error) { return id, err }
+	t.Cleanup(func() { lookupRecordIDFn = orig })
+}
+
+func TestPutThenGetIsCacheHit(t *testing.T) {
+	withStubRecordID(t, "20190429T000000", nil)
+
+	c, err := Load(filepath.Join(t.TempDir(), "cache.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	result := checker.DomainResult{Domain: "example.com"}
+	if err := c.Put(Entry{
+		Domain: "example.com",
+		Result: result,
+		MaxAge: 24 * time.Hour,
+	}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	entry, ok, stale := c.Get("example.com", time.Hour)
+	if !ok {
+		t.Fatal("Get: expected a cached entry")
+	}
+	if stale {
+		t.Fatal("Get: expected a fresh entry right after Put, got stale")
+	}
+	if entry.RecordID != "20190429T000000" {
+		t.Fatalf("Get: RecordID = %q, want the id= token fetched at Put time", entry.RecordID)
+	}
+}
+
+func TestGetIsStaleWhenRecordIDChanges(t *testing.T) {
+	withStubRecordID(t, "old-id", nil)
+
+	c, err := Load(filepath.Join(t.TempDir(), "cache.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := c.Put(Entry{Domain: "example.com", MaxAge: 24 * time.Hour}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	withStubRecordID(t, "new-id", nil)
+	_, ok, stale := c.Get("example.com", time.Hour)
+	if !ok {
+		t.Fatal("Get: expected a cached entry")
+	}
+	if !stale {
+		t.Fatal("Get: expected stale=true once the published id= token changes")
+	}
+}
+
+func TestNeedsRefreshBackoff(t *testing.T) {
+	e := Entry{Backoff: true, LastUpdate: time.Now()}
+	if e.needsRefresh(time.Hour, "") {
+		t.Fatal("needsRefresh: backed-off entry should not need refresh before negativeCacheBackoff elapses")
+	}
+	e.LastUpdate = time.Now().Add(-negativeCacheBackoff - time.Second)
+	if !e.needsRefresh(time.Hour, "") {
+		t.Fatal("needsRefresh: backed-off entry should need refresh once negativeCacheBackoff elapses")
+	}
+}
+
+func TestNeedsRefreshMaxAgeHalfLife(t *testing.T) {
+	e := Entry{LastUpdate: time.Now().Add(-31 * time.Minute), MaxAge: time.Hour}
+	if !e.needsRefresh(24*time.Hour, "") {
+		t.Fatal("needsRefresh: entry past half its MaxAge should need refresh")
+	}
+	e.LastUpdate = time.Now().Add(-29 * time.Minute)
+	if e.needsRefresh(24*time.Hour, "") {
+		t.Fatal("needsRefresh: entry within half its MaxAge should not need refresh")
+	}
+}
+
+func TestEvictRemovesOnlyStaleEntries(t *testing.T) {
+	withStubRecordID(t, "id", nil)
+
+	c, err := Load(filepath.Join(t.TempDir(), "cache.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := c.Put(Entry{Domain: "stale.com"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := c.Put(Entry{Domain: "fresh.com"}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	c.mu.Lock()
+	stale := c.entries["stale.com"]
+	stale.LastUse = time.Now().Add(-2 * time.Hour)
+	c.entries["stale.com"] = stale
+	c.mu.Unlock()
+
+	removed, err := c.Evict(time.Hour)
+	if err != nil {
+		t.Fatalf("Evict: %v", err)
+	}
+	if removed != 1 {
+		t.Fatalf("Evict: removed = %d, want 1", removed)
+	}
+	if _, ok, _ := c.Get("stale.com", time.Hour); ok {
+		t.Fatal("Evict: stale.com should have been removed")
+	}
+	if _, ok, _ := c.Get("fresh.com", time.Hour); !ok {
+		t.Fatal("Evict: fresh.com should not have been removed")
+	}
+}
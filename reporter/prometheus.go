@@ -0,0 +1,74 @@
+package reporter
+
+import (
+	"context"
+	"time"
+
+	"github.com/EFForg/starttls-backend/validator"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prometheus records validation outcomes as Prometheus metrics:
+//   - starttls_validation_total{domain,result}
+//   - starttls_validation_duration_seconds
+//   - starttls_policy_last_success_timestamp{domain}
+//
+// Register it once with prometheus.MustRegister before adding it to
+// Validator.Reporters.
+type Prometheus struct {
+	total       *prometheus.CounterVec
+	duration    prometheus.Histogram
+	lastSuccess *prometheus.GaugeVec
+}
+
+// NewPrometheus constructs a Prometheus reporter with its metrics created
+// but not yet registered.
+func NewPrometheus() *Prometheus {
+	return &Prometheus{
+		total: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "starttls_validation_total",
+			Help: "Count of domain validations, by result.",
+		}, []string{"domain", "result"}),
+		duration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name: "starttls_validation_duration_seconds",
+			Help: "Time spent checking a single domain's policy.",
+		}),
+		lastSuccess: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "starttls_policy_last_success_timestamp",
+			Help: "Unix timestamp of the last successful validation, by domain.",
+		}, []string{"domain"}),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (p *Prometheus) Describe(ch chan<- *prometheus.Desc) {
+	p.total.Describe(ch)
+	p.duration.Describe(ch)
+	p.lastSuccess.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (p *Prometheus) Collect(ch chan<- prometheus.Metric) {
+	p.total.Collect(ch)
+	p.duration.Collect(ch)
+	p.lastSuccess.Collect(ch)
+}
+
+// Report implements validator.Reporter.
+func (p *Prometheus) Report(ctx context.Context, event validator.ValidationEvent) error {
+	result := "success"
+	if !event.Success {
+		result = "failure"
+	}
+	p.total.WithLabelValues(event.Result.Domain, result).Inc()
+	if event.Success {
+		p.lastSuccess.WithLabelValues(event.Result.Domain).Set(float64(time.Now().Unix()))
+	}
+	p.ObserveDuration(event.Duration)
+	return nil
+}
+
+// ObserveDuration records how long a single domain check took.
+func (p *Prometheus) ObserveDuration(d time.Duration) {
+	p.duration.Observe(d.Seconds())
+}
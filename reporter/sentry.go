@@ -0,0 +1,41 @@
+// Package reporter provides built-in validator.Reporter implementations:
+// Sentry (the validator package's original hard-coded behavior), a
+// Prometheus collector, and a generic HTTP webhook.
+package reporter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/EFForg/starttls-backend/validator"
+	"github.com/getsentry/raven-go"
+)
+
+// captureMessageFn is a var so tests can stub out the real Sentry client.
+var captureMessageFn = raven.CaptureMessageAndWait
+
+// Sentry reports failed validations to Sentry. Successes are ignored, as
+// are Advisory failures and SoftMandatory failures covered by an active
+// PolicyOverride; HardMandatory failures always alert.
+type Sentry struct{}
+
+// Report implements validator.Reporter.
+func (Sentry) Report(ctx context.Context, event validator.ValidationEvent) error {
+	if event.Success {
+		return nil
+	}
+	if event.Severity == validator.Advisory {
+		return nil
+	}
+	if event.Severity == validator.SoftMandatory && event.Overridden {
+		return nil
+	}
+	captureMessageFn("Validation failed for previously validated domain",
+		map[string]string{
+			"validatorName": event.ValidatorName,
+			"domain":        event.Result.Domain,
+			"status":        fmt.Sprintf("%d", event.Result.Status),
+		},
+		event.Result)
+	return nil
+}
@@ -0,0 +1,97 @@
+package reporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/EFForg/starttls-backend/validator"
+	"github.com/getsentry/raven-go"
+)
+
+// withStubCapture stubs the real Sentry client for the duration of a test,
+// counting calls instead of alerting, and restores the real implementation
+// on cleanup.
+func withStubCapture(t *testing.T) *int {
+	t.Helper()
+	calls := 0
+	orig := captureMessageFn
+	captureMessageFn = func(message string, tags map[string]string, interfaces ...raven.Interface) string {
+		calls++
+		return ""
+	}
+	t.Cleanup(func() { captureMessageFn = orig })
+	return &calls
+}
+
+func TestSentryReportSuppressesAdvisoryFailures(t *testing.T) {
+	calls := withStubCapture(t)
+
+	err := Sentry{}.Report(context.Background(), validator.ValidationEvent{
+		Severity: validator.Advisory,
+	})
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if *calls != 0 {
+		t.Fatalf("Report: called Sentry %d times, want 0 for an Advisory failure", *calls)
+	}
+}
+
+func TestSentryReportSuppressesOverriddenSoftMandatoryFailures(t *testing.T) {
+	calls := withStubCapture(t)
+
+	err := Sentry{}.Report(context.Background(), validator.ValidationEvent{
+		Severity:   validator.SoftMandatory,
+		Overridden: true,
+	})
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if *calls != 0 {
+		t.Fatalf("Report: called Sentry %d times, want 0 for an overridden SoftMandatory failure", *calls)
+	}
+}
+
+func TestSentryReportAlertsOnUnoverriddenSoftMandatoryFailure(t *testing.T) {
+	calls := withStubCapture(t)
+
+	err := Sentry{}.Report(context.Background(), validator.ValidationEvent{
+		Severity:   validator.SoftMandatory,
+		Overridden: false,
+	})
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if *calls != 1 {
+		t.Fatalf("Report: called Sentry %d times, want 1 for an unoverridden SoftMandatory failure", *calls)
+	}
+}
+
+func TestSentryReportAlertsOnHardMandatoryFailure(t *testing.T) {
+	calls := withStubCapture(t)
+
+	err := Sentry{}.Report(context.Background(), validator.ValidationEvent{
+		Severity: validator.HardMandatory,
+	})
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if *calls != 1 {
+		t.Fatalf("Report: called Sentry %d times, want 1 for a HardMandatory failure", *calls)
+	}
+}
+
+func TestSentryReportIgnoresSuccesses(t *testing.T) {
+	calls := withStubCapture(t)
+
+	err := Sentry{}.Report(context.Background(), validator.ValidationEvent{
+		Severity: validator.HardMandatory,
+		Success:  true,
+	})
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if *calls != 0 {
+		t.Fatalf("Report: called Sentry %d times, want 0 for a success", *calls)
+	}
+}
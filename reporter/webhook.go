@@ -0,0 +1,46 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/EFForg/starttls-backend/validator"
+)
+
+// Webhook POSTs a JSON-encoded validator.ValidationEvent (including the
+// full checker.DomainResult) to URL after each check, so operators can wire
+// Validator into their own incident-response systems.
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+// Report implements validator.Reporter.
+func (w Webhook) Report(ctx context.Context, event validator.ValidationEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("reporter: marshaling webhook event: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("reporter: building webhook request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("reporter: delivering webhook to %s: %v", w.URL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("reporter: webhook %s responded with status %d", w.URL, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package tlsrpt
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// domainCounters tracks one domain's session counts for the current
+// reporting period.
+type domainCounters struct {
+	policyDomain string
+	policyType   PolicyType
+	success      int
+	failures     map[ResultType]int
+}
+
+// Accumulator collects per-domain success/failure counts between report
+// rotations and turns them into Report documents. It is safe for concurrent
+// use; Validator calls RecordSuccess and RecordFailure from its check loop
+// while a separate goroutine
+// calls Rotate on a timer.
+type Accumulator struct {
+	// OrganizationName is reported as the report's "organization-name".
+	OrganizationName string
+	// ContactInfo is reported as the report's "contact-info", e.g. an
+	// abuse or support email address.
+	ContactInfo string
+
+	mu          sync.Mutex
+	periodStart time.Time
+	counters    map[string]*domainCounters
+}
+
+// NewAccumulator returns an Accumulator that starts counting from now.
+func NewAccumulator(organizationName, contactInfo string) *Accumulator {
+	return &Accumulator{
+		OrganizationName: organizationName,
+		ContactInfo:      contactInfo,
+		periodStart:      time.Now(),
+		counters:         make(map[string]*domainCounters),
+	}
+}
+
+// RecordSuccess notes a successful validation session for domain under the
+// given policy type.
+func (a *Accumulator) RecordSuccess(domain string, policyType PolicyType) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.counterFor(domain, policyType).success++
+}
+
+// RecordFailure notes a failed validation session for domain, classified by
+// result.
+func (a *Accumulator) RecordFailure(domain string, policyType PolicyType, result ResultType) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	c := a.counterFor(domain, policyType)
+	c.failures[result]++
+}
+
+// counterFor returns the domainCounters for domain, creating it if absent.
+// Must be called with a.mu held.
+func (a *Accumulator) counterFor(domain string, policyType PolicyType) *domainCounters {
+	c, ok := a.counters[domain]
+	if !ok {
+		c = &domainCounters{
+			policyDomain: domain,
+			policyType:   policyType,
+			failures:     make(map[ResultType]int),
+		}
+		a.counters[domain] = c
+	}
+	return c
+}
+
+// Rotate clears the accumulated counters and returns one Report per domain
+// that had any activity during the period, ready for delivery to that
+// domain's RUA. Call this on a timer (default 24h, per RFC 8460 §5).
+func (a *Accumulator) Rotate() map[string]Report {
+	a.mu.Lock()
+	counters := a.counters
+	start := a.periodStart
+	a.counters = make(map[string]*domainCounters)
+	a.periodStart = time.Now()
+	orgName, contactInfo := a.OrganizationName, a.ContactInfo
+	a.mu.Unlock()
+
+	end := time.Now()
+	reports := make(map[string]Report, len(counters))
+	for domain, c := range counters {
+		var details []FailureDetail
+		failureCount := 0
+		for resultType, count := range c.failures {
+			details = append(details, FailureDetail{
+				ResultType:         resultType,
+				FailedSessionCount: count,
+			})
+			failureCount += count
+		}
+		reports[domain] = Report{
+			OrganizationName: orgName,
+			ContactInfo:      contactInfo,
+			ReportID:         fmt.Sprintf("%s-%d", domain, start.Unix()),
+			DateRange: DateRange{
+				StartDatetime: start,
+				EndDatetime:   end,
+			},
+			Policies: []PolicyResult{{
+				Policy: Policy{
+					PolicyType:   c.policyType,
+					PolicyDomain: domain,
+				},
+				Summary: Summary{
+					TotalSuccessfulSessionCount: c.success,
+					TotalFailureSessionCount:    failureCount,
+				},
+				FailureDetails: details,
+			}},
+		}
+	}
+	return reports
+}
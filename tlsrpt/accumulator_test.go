@@ -0,0 +1,47 @@
+package tlsrpt
+
+import "testing"
+
+func TestAccumulatorRotateReportsSuccessAndFailureCounts(t *testing.T) {
+	a := NewAccumulator("Test Org", "abuse@example.com")
+
+	a.RecordSuccess("example.com", PolicyTypeSTS)
+	a.RecordSuccess("example.com", PolicyTypeSTS)
+	a.RecordFailure("example.com", PolicyTypeSTS, ResultCertificateExpired)
+	a.RecordFailure("example.com", PolicyTypeSTS, ResultCertificateExpired)
+	a.RecordFailure("example.com", PolicyTypeSTS, ResultSTSPolicyInvalid)
+
+	reports := a.Rotate()
+	report, ok := reports["example.com"]
+	if !ok {
+		t.Fatal("Rotate: expected a report for example.com")
+	}
+	if report.OrganizationName != "Test Org" || report.ContactInfo != "abuse@example.com" {
+		t.Fatalf("Rotate: report = %+v, want OrganizationName/ContactInfo from the Accumulator", report)
+	}
+	if len(report.Policies) != 1 {
+		t.Fatalf("Rotate: len(Policies) = %d, want 1", len(report.Policies))
+	}
+	summary := report.Policies[0].Summary
+	if summary.TotalSuccessfulSessionCount != 2 {
+		t.Fatalf("Rotate: TotalSuccessfulSessionCount = %d, want 2", summary.TotalSuccessfulSessionCount)
+	}
+	if summary.TotalFailureSessionCount != 3 {
+		t.Fatalf("Rotate: TotalFailureSessionCount = %d, want 3", summary.TotalFailureSessionCount)
+	}
+	if got := len(report.Policies[0].FailureDetails); got != 2 {
+		t.Fatalf("Rotate: len(FailureDetails) = %d, want 2 distinct result types", got)
+	}
+}
+
+func TestAccumulatorRotateClearsCounters(t *testing.T) {
+	a := NewAccumulator("Test Org", "abuse@example.com")
+	a.RecordSuccess("example.com", PolicyTypeSTS)
+
+	if reports := a.Rotate(); len(reports) != 1 {
+		t.Fatalf("first Rotate: len(reports) = %d, want 1", len(reports))
+	}
+	if reports := a.Rotate(); len(reports) != 0 {
+		t.Fatalf("second Rotate: len(reports) = %d, want 0 once counters are cleared", len(reports))
+	}
+}
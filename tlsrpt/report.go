@@ -0,0 +1,87 @@
+// Package tlsrpt implements RFC 8460 SMTP TLS Reporting: it turns
+// validator.Validator's per-domain success/failure events into aggregate
+// reports and delivers them to the RUA endpoints a domain publishes in its
+// `_smtp._tls.<domain>` TXT record. This replaces a silent Sentry alert with
+// an actionable signal the domain owner themselves can act on.
+package tlsrpt
+
+import (
+	"time"
+)
+
+// ResultType is one of the "result-type" strings defined in RFC 8460 §4.3,
+// plus the starttls-policy-list-specific additions we report on.
+type ResultType string
+
+// Result types we accumulate. Each is reported as-is as a failure detail's
+// "result-type"; the sts-* types are kept distinct so operators can tell STS
+// policy problems apart from certificate problems.
+const (
+	ResultStarttlsNotSupported    ResultType = "starttls-not-supported"
+	ResultCertificateHostMismatch ResultType = "certificate-host-mismatch"
+	ResultCertificateExpired      ResultType = "certificate-expired"
+	ResultCertificateNotTrusted   ResultType = "certificate-not-trusted"
+	ResultValidationFailure       ResultType = "validation-failure"
+	ResultSTSPolicyFetchError     ResultType = "sts-policy-fetch-error"
+	ResultSTSPolicyInvalid        ResultType = "sts-policy-invalid"
+	ResultSTSWebPKIInvalid        ResultType = "sts-webpki-invalid"
+)
+
+// PolicyType identifies the kind of policy a report's summary applies to,
+// per RFC 8460 §4.3.
+type PolicyType string
+
+// Policy types defined by RFC 8460 §4.3.
+const (
+	PolicyTypeSTS  PolicyType = "sts"
+	PolicyTypeTLSA PolicyType = "tlsa"
+	PolicyTypeNone PolicyType = "no-policy-found"
+)
+
+// DateRange is the "date-range" object of an aggregate report.
+type DateRange struct {
+	StartDatetime time.Time `json:"start-datetime"`
+	EndDatetime   time.Time `json:"end-datetime"`
+}
+
+// Summary is the "summary" object of a policy's report entry.
+type Summary struct {
+	TotalSuccessfulSessionCount int `json:"total-successful-session-count"`
+	TotalFailureSessionCount    int `json:"total-failure-session-count"`
+}
+
+// FailureDetail is one entry of a policy's "failure-details" array.
+type FailureDetail struct {
+	ResultType            ResultType `json:"result-type"`
+	SendingMTAIP          string     `json:"sending-mta-ip,omitempty"`
+	ReceivingMXHostname   string     `json:"receiving-mx-hostname,omitempty"`
+	ReceivingMXHelo       string     `json:"receiving-mx-helo,omitempty"`
+	ReceivingIP           string     `json:"receiving-ip,omitempty"`
+	FailedSessionCount    int        `json:"failed-session-count"`
+	AdditionalInformation string     `json:"additional-information,omitempty"`
+	FailureReasonCode     string     `json:"failure-reason-code,omitempty"`
+}
+
+// Policy is the "policy" object identifying what was evaluated.
+type Policy struct {
+	PolicyType   PolicyType `json:"policy-type"`
+	PolicyString []string   `json:"policy-string,omitempty"`
+	PolicyDomain string     `json:"policy-domain"`
+	MXHost       []string   `json:"mx-host,omitempty"`
+}
+
+// PolicyResult is one entry of the report's top-level "policies" array.
+type PolicyResult struct {
+	Policy         Policy          `json:"policy"`
+	Summary        Summary         `json:"summary"`
+	FailureDetails []FailureDetail `json:"failure-details,omitempty"`
+}
+
+// Report is a complete RFC 8460 §4.3 aggregate report document.
+type Report struct {
+	OrganizationName string         `json:"organization-name"`
+	DateRange        DateRange      `json:"date-range"`
+	ContactInfo      string         `json:"contact-info"`
+	ReportID         string         `json:"report-id"`
+	Policies         []PolicyResult `json:"policies"`
+}
@@ -0,0 +1,181 @@
+package tlsrpt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strings"
+)
+
+// ReportSender delivers a Report for domain to one RUA URI. Operators can
+// implement this to plug in their own transport; MailtoSender and
+// HTTPSSender cover the two schemes RFC 8460 requires support for.
+type ReportSender interface {
+	Send(ctx context.Context, domain string, rua *url.URL, report Report) error
+}
+
+// lookupTXTFn is a var so tests can stub out the live DNS lookup.
+var lookupTXTFn = net.LookupTXT
+
+// LookupRUAs returns the rua= URIs published in domain's
+// `_smtp._tls.<domain>` TXT record, per RFC 8460 §3.
+func LookupRUAs(domain string) ([]*url.URL, error) {
+	records, err := lookupTXTFn("_smtp._tls." + domain)
+	if err != nil {
+		return nil, fmt.Errorf("tlsrpt: TXT lookup for %s: %v", domain, err)
+	}
+	var ruas []*url.URL
+	for _, record := range records {
+		if !strings.HasPrefix(record, "v=TLSRPTv1") {
+			continue
+		}
+		for _, field := range strings.Split(record, ";") {
+			field = strings.TrimSpace(field)
+			if !strings.HasPrefix(field, "rua=") {
+				continue
+			}
+			for _, uri := range strings.Split(strings.TrimPrefix(field, "rua="), ",") {
+				u, err := url.Parse(strings.TrimSpace(uri))
+				if err != nil {
+					continue
+				}
+				ruas = append(ruas, u)
+			}
+		}
+	}
+	if len(ruas) == 0 {
+		return nil, fmt.Errorf("tlsrpt: no rua found in TLSRPT record for %s", domain)
+	}
+	return ruas, nil
+}
+
+// Deliver sends report to every RUA published for domain, using httpsSender for
+// https: URIs and mailSender for mailto: URIs. It returns the first error
+// encountered, after attempting every RUA.
+func Deliver(ctx context.Context, domain string, ruas []*url.URL, report Report, httpsSender, mailSender ReportSender) error {
+	var firstErr error
+	for _, rua := range ruas {
+		var sender ReportSender
+		switch rua.Scheme {
+		case "https":
+			sender = httpsSender
+		case "mailto":
+			sender = mailSender
+		default:
+			continue
+		}
+		if sender == nil {
+			continue
+		}
+		if err := sender.Send(ctx, domain, rua, report); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// gzipJSON marshals report to JSON and gzip-compresses it, per RFC 8460
+// §4.2 ("Content-Type: application/tlsrpt+gzip").
+func gzipJSON(report Report) ([]byte, error) {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("tlsrpt: marshaling report: %v", err)
+	}
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, fmt.Errorf("tlsrpt: gzipping report: %v", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("tlsrpt: gzipping report: %v", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// HTTPSSender delivers reports by POSTing the gzip'd JSON report to an
+// https: RUA, per RFC 8460 §4.2.
+type HTTPSSender struct {
+	Client *http.Client
+}
+
+// Send implements ReportSender.
+func (s HTTPSSender) Send(ctx context.Context, domain string, rua *url.URL, report Report) error {
+	body, err := gzipJSON(report)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rua.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("tlsrpt: building request for %s: %v", rua, err)
+	}
+	req.Header.Set("Content-Type", "application/tlsrpt+gzip")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("tlsrpt: delivering report for %s to %s: %v", domain, rua, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("tlsrpt: %s rejected report for %s with status %d", rua, domain, resp.StatusCode)
+	}
+	return nil
+}
+
+// MailtoSender delivers reports by submitting a MIME message, with the
+// gzip'd JSON report attached, via SMTP to Relay.
+type MailtoSender struct {
+	// Relay is the "host:port" of the SMTP relay used to submit reports.
+	Relay string
+	// From is the envelope and header From address.
+	From string
+}
+
+// Send implements ReportSender.
+func (s MailtoSender) Send(ctx context.Context, domain string, rua *url.URL, report Report) error {
+	body, err := gzipJSON(report)
+	if err != nil {
+		return err
+	}
+	addr, err := mail.ParseAddress(rua.Opaque)
+	if err != nil {
+		return fmt.Errorf("tlsrpt: invalid mailto RUA %s: %v", rua, err)
+	}
+	msg := buildReportMessage(s.From, addr.Address, domain, body)
+
+	host, _, err := net.SplitHostPort(s.Relay)
+	if err != nil {
+		return fmt.Errorf("tlsrpt: invalid relay %q: %v", s.Relay, err)
+	}
+	if err := smtp.SendMail(s.Relay, nil, s.From, []string{addr.Address}, msg); err != nil {
+		return fmt.Errorf("tlsrpt: delivering report for %s via %s (%s): %v", domain, s.Relay, host, err)
+	}
+	return nil
+}
+
+// buildReportMessage wraps the gzip'd report in a minimal MIME message, per
+// RFC 8460 §4.1's guidance to attach the report as
+// application/tlsrpt+gzip.
+func buildReportMessage(from, to, domain string, gzippedReport []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to)
+	fmt.Fprintf(&buf, "Subject: Report Domain: %s Submitter: %s\r\n", domain, from)
+	fmt.Fprintf(&buf, "TLS-Report-Domain: %s\r\n", domain)
+	buf.WriteString("Content-Type: application/tlsrpt+gzip\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	buf.WriteString(base64.StdEncoding.EncodeToString(gzippedReport))
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
@@ -0,0 +1,69 @@
+package tlsrpt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+// withStubTXT stubs the live DNS lookup for the duration of a test,
+// restoring the real implementation on cleanup.
+func withStubTXT(t *testing.T, records []string, err error) {
+	t.Helper()
+	orig := lookupTXTFn
+	lookupTXTFn = func(string) ([]string, error) { return records, err }
+	t.Cleanup(func() { lookupTXTFn = orig })
+}
+
+func TestLookupRUAsParsesAndSplitsRUAField(t *testing.T) {
+	withStubTXT(t, []string{
+		"v=TLSRPTv1; rua=https://example.com/report,mailto:reports@example.com",
+	}, nil)
+
+	ruas, err := LookupRUAs("example.com")
+	if err != nil {
+		t.Fatalf("LookupRUAs: %v", err)
+	}
+	if len(ruas) != 2 {
+		t.Fatalf("LookupRUAs: got %d RUAs, want 2", len(ruas))
+	}
+	if ruas[0].Scheme != "https" || ruas[1].Scheme != "mailto" {
+		t.Fatalf("LookupRUAs: schemes = %q, %q, want https then mailto", ruas[0].Scheme, ruas[1].Scheme)
+	}
+}
+
+func TestLookupRUAsIgnoresNonTLSRPTRecords(t *testing.T) {
+	withStubTXT(t, []string{"v=spf1 include:_spf.example.com ~all"}, nil)
+
+	if _, err := LookupRUAs("example.com"); err == nil {
+		t.Fatal("LookupRUAs: expected an error when no v=TLSRPTv1 record is published")
+	}
+}
+
+func TestGzipJSONRoundTrips(t *testing.T) {
+	report := Report{OrganizationName: "Test Org", ReportID: "example.com-1"}
+
+	data, err := gzipJSON(report)
+	if err != nil {
+		t.Fatalf("gzipJSON: %v", err)
+	}
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	decompressed, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("reading gzip stream: %v", err)
+	}
+
+	var got Report
+	if err := json.Unmarshal(decompressed, &got); err != nil {
+		t.Fatalf("unmarshaling decompressed report: %v", err)
+	}
+	if got.OrganizationName != report.OrganizationName || got.ReportID != report.ReportID {
+		t.Fatalf("round-tripped report = %+v, want %+v", got, report)
+	}
+}
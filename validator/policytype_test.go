@@ -0,0 +1,25 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/EFForg/starttls-backend/tlsrpt"
+)
+
+func TestPolicyType(t *testing.T) {
+	tests := []struct {
+		mode AuthMode
+		want tlsrpt.PolicyType
+	}{
+		{AuthModeOpportunistic, tlsrpt.PolicyTypeNone},
+		{AuthModeMTASTS, tlsrpt.PolicyTypeSTS},
+		{AuthModeDANE, tlsrpt.PolicyTypeTLSA},
+		{AuthModeBoth, tlsrpt.PolicyTypeSTS},
+	}
+	for _, tt := range tests {
+		got := policyType(CheckResult{AuthMode: tt.mode})
+		if got != tt.want {
+			t.Errorf("policyType(AuthMode=%s) = %s, want %s", tt.mode, got, tt.want)
+		}
+	}
+}
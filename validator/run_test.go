@@ -0,0 +1,107 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/EFForg/starttls-backend/models"
+)
+
+// fakeStore is a minimal DomainPolicyStore backed by an in-memory list, for
+// exercising Run without a real database.
+type fakeStore struct {
+	domains []string
+}
+
+func (s *fakeStore) DomainsToValidate() ([]string, error) { return s.domains, nil }
+
+func (s *fakeStore) GetDomain(name string) (models.Domain, error) {
+	return models.Domain{Name: name}, nil
+}
+
+func (s *fakeStore) RecordOverride(domain string, override PolicyOverride) error { return nil }
+
+func (s *fakeStore) GetOverride(domain string) (PolicyOverride, error) { return PolicyOverride{}, nil }
+
+// TestRunDoesNotDeadlockWithMoreDueDomainsThanConcurrency is a regression
+// test for a dispatcher deadlock: with more domains simultaneously due than
+// Concurrency, the inner dispatch loop in Run used to block forever pushing
+// jobs while every worker was itself blocked trying to write an unbuffered
+// results channel nothing was reading.
+func TestRunDoesNotDeadlockWithMoreDueDomainsThanConcurrency(t *testing.T) {
+	const numDomains = 8
+	const concurrency = 2
+
+	domains := make([]string, numDomains)
+	for i := range domains {
+		domains[i] = fmt.Sprintf("domain%d.example", i)
+	}
+
+	var checked int32
+	v := &Validator{
+		Name:  "test",
+		Store: &fakeStore{domains: domains},
+		CheckPerformer: func(domain models.Domain) CheckResult {
+			atomic.AddInt32(&checked, 1)
+			return CheckResult{}
+		},
+		Concurrency:       concurrency,
+		PerDomainInterval: time.Hour,
+		Jitter:            time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- v.Run(ctx) }()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return; dispatcher likely deadlocked")
+	}
+
+	if got := atomic.LoadInt32(&checked); got != numDomains {
+		t.Fatalf("checked %d domains, want all %d (some were never dispatched)", got, numDomains)
+	}
+}
+
+// TestRunWithoutCheckPerformerIsRaceFree is a regression test for a data
+// race in checkPolicy's lazy default-CheckPerformer init: Run dispatches to
+// Concurrency workers, which used to read and write v.CheckPerformer
+// unsynchronized, racing whenever more than one worker's first job landed
+// at the same time. Run this under -race; it previously reported a DATA
+// RACE on this path.
+func TestRunWithoutCheckPerformerIsRaceFree(t *testing.T) {
+	const numDomains = 8
+	const concurrency = 8
+
+	domains := make([]string, numDomains)
+	for i := range domains {
+		domains[i] = fmt.Sprintf("domain%d.example", i)
+	}
+
+	v := &Validator{
+		Name:              "test",
+		Store:             &fakeStore{domains: domains},
+		Concurrency:       concurrency,
+		PerDomainInterval: time.Hour,
+		Jitter:            time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() { done <- v.Run(ctx) }()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return")
+	}
+}
@@ -0,0 +1,162 @@
+package validator
+
+import (
+	"container/heap"
+	"math/rand"
+	"time"
+)
+
+// domainJob is one domain's entry in the scheduler's min-heap, ordered by
+// nextCheck so the dispatcher always pops whichever domain is due soonest.
+type domainJob struct {
+	name      string
+	nextCheck time.Time
+	backoff   time.Duration
+	index     int
+}
+
+// jobHeap is a container/heap of domainJob, ordered by nextCheck ascending.
+type jobHeap []*domainJob
+
+func (h jobHeap) Len() int           { return len(h) }
+func (h jobHeap) Less(i, j int) bool { return h[i].nextCheck.Before(h[j].nextCheck) }
+func (h jobHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *jobHeap) Push(x interface{}) {
+	job := x.(*domainJob)
+	job.index = len(*h)
+	*h = append(*h, job)
+}
+
+func (h *jobHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	job := old[n-1]
+	old[n-1] = nil
+	job.index = -1
+	*h = old[:n-1]
+	return job
+}
+
+// scheduler keeps a min-heap of per-domain next-check times, so a large
+// policy list gets spread across PerDomainInterval instead of everyone
+// being re-checked in one synchronized sweep.
+type scheduler struct {
+	jobs    jobHeap
+	known   map[string]*domainJob
+	jitter  time.Duration
+	minWait time.Duration
+}
+
+func newScheduler(jitter time.Duration) *scheduler {
+	s := &scheduler{known: make(map[string]*domainJob), minWait: time.Second}
+	heap.Init(&s.jobs)
+	s.jitter = jitter
+	return s
+}
+
+// ensure adds domain to the scheduler, due immediately (with jitter), if it
+// isn't already tracked.
+func (s *scheduler) ensure(domain string) {
+	if _, ok := s.known[domain]; ok {
+		return
+	}
+	job := &domainJob{name: domain, nextCheck: time.Now().Add(s.randomJitter())}
+	s.known[domain] = job
+	heap.Push(&s.jobs, job)
+}
+
+// randomJitter returns a random duration in [0, s.jitter), so scheduling a
+// large batch of domains doesn't produce synchronized DNS/TCP bursts.
+func (s *scheduler) randomJitter() time.Duration {
+	if s.jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(s.jitter)))
+}
+
+// nextDue returns the domain due soonest, and how long to wait for it (zero
+// or negative if it's already due). It's a no-op if the scheduler is empty.
+func (s *scheduler) nextDue() (string, time.Duration, bool) {
+	if s.jobs.Len() == 0 {
+		return "", s.minWait, false
+	}
+	job := s.jobs[0]
+	return job.name, time.Until(job.nextCheck), true
+}
+
+// pop removes and returns the domain due soonest. Callers must have checked
+// nextDue first.
+func (s *scheduler) pop() *domainJob {
+	return heap.Pop(&s.jobs).(*domainJob)
+}
+
+// reschedule reinserts job into the heap after a check completes: on
+// success it's due again in perDomainInterval (plus jitter) and backoff
+// resets; on failure backoff doubles, bounded by maxBackoff.
+func (s *scheduler) reschedule(job *domainJob, success bool, perDomainInterval, maxBackoff time.Duration) {
+	if success {
+		job.backoff = 0
+		job.nextCheck = time.Now().Add(perDomainInterval + s.randomJitter())
+	} else {
+		if job.backoff == 0 {
+			job.backoff = perDomainInterval
+		} else {
+			job.backoff *= 2
+		}
+		if job.backoff > maxBackoff {
+			job.backoff = maxBackoff
+		}
+		job.nextCheck = time.Now().Add(job.backoff + s.randomJitter())
+	}
+	heap.Push(&s.jobs, job)
+}
+
+// rateLimiter is a simple token bucket: it allows up to `rate` operations
+// per second, refilled once per tick, so a pool of workers can't all dial
+// out at once even if many domains are due simultaneously.
+type rateLimiter struct {
+	tokens chan struct{}
+}
+
+// newRateLimiter returns a rateLimiter that refills `rate` tokens every
+// second. A non-positive rate disables limiting: it returns nil, and wait
+// on a nil rateLimiter always reports true without blocking.
+func newRateLimiter(rate int) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	rl := &rateLimiter{tokens: make(chan struct{}, rate)}
+	for i := 0; i < rate; i++ {
+		rl.tokens <- struct{}{}
+	}
+	go rl.refill(rate)
+	return rl
+}
+
+func (rl *rateLimiter) refill(rate int) {
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+	for range ticker.C {
+		select {
+		case rl.tokens <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// wait blocks until a token is available or ctx is done.
+func (rl *rateLimiter) wait(done <-chan struct{}) bool {
+	if rl == nil {
+		return true
+	}
+	select {
+	case <-rl.tokens:
+		return true
+	case <-done:
+		return false
+	}
+}
@@ -0,0 +1,87 @@
+package validator
+
+import (
+	"container/heap"
+	"testing"
+	"time"
+)
+
+func TestSchedulerNextDueOrdersByNextCheck(t *testing.T) {
+	s := newScheduler(0)
+	heap.Push(&s.jobs, &domainJob{name: "later", nextCheck: time.Now().Add(time.Hour)})
+	heap.Push(&s.jobs, &domainJob{name: "sooner", nextCheck: time.Now()})
+
+	name, wait, ok := s.nextDue()
+	if !ok || name != "sooner" {
+		t.Fatalf("nextDue: name = %q, ok = %v, want %q, true", name, ok, "sooner")
+	}
+	if wait > 0 {
+		t.Fatalf("nextDue: wait = %v, want <= 0 (already due)", wait)
+	}
+	job := s.pop()
+	if job.name != "sooner" {
+		t.Fatalf("pop: name = %q, want %q", job.name, "sooner")
+	}
+
+	name, wait, ok = s.nextDue()
+	if !ok || name != "later" {
+		t.Fatalf("nextDue: name = %q, ok = %v, want %q, true", name, ok, "later")
+	}
+	if wait <= 0 {
+		t.Fatalf("nextDue: wait = %v, want positive (job scheduled in the future)", wait)
+	}
+}
+
+func TestSchedulerEnsureIsIdempotent(t *testing.T) {
+	s := newScheduler(0)
+	s.ensure("example.com")
+	s.ensure("example.com")
+	if s.jobs.Len() != 1 {
+		t.Fatalf("jobs.Len() = %d, want 1 (ensure should not duplicate a known domain)", s.jobs.Len())
+	}
+}
+
+func TestSchedulerRescheduleSuccessResetsBackoff(t *testing.T) {
+	s := newScheduler(0)
+	job := &domainJob{name: "example.com", backoff: time.Hour}
+	s.reschedule(job, true, time.Minute, time.Hour)
+	if job.backoff != 0 {
+		t.Fatalf("backoff = %v, want 0 after a success", job.backoff)
+	}
+	if wait := time.Until(job.nextCheck); wait <= 0 || wait > time.Minute {
+		t.Fatalf("nextCheck in %v, want roughly perDomainInterval (1m) from now", wait)
+	}
+}
+
+func TestSchedulerRescheduleFailureBacksOffExponentially(t *testing.T) {
+	s := newScheduler(0)
+	job := &domainJob{name: "example.com"}
+	perDomainInterval, maxBackoff := time.Minute, 10*time.Minute
+
+	s.reschedule(job, false, perDomainInterval, maxBackoff)
+	if job.backoff != perDomainInterval {
+		t.Fatalf("backoff after first failure = %v, want %v", job.backoff, perDomainInterval)
+	}
+
+	s.reschedule(job, false, perDomainInterval, maxBackoff)
+	if job.backoff != 2*perDomainInterval {
+		t.Fatalf("backoff after second failure = %v, want %v", job.backoff, 2*perDomainInterval)
+	}
+
+	for i := 0; i < 10; i++ {
+		s.reschedule(job, false, perDomainInterval, maxBackoff)
+	}
+	if job.backoff != maxBackoff {
+		t.Fatalf("backoff = %v, want it capped at maxBackoff %v", job.backoff, maxBackoff)
+	}
+}
+
+func TestRateLimiterNilDisablesLimiting(t *testing.T) {
+	rl := newRateLimiter(0)
+	if rl != nil {
+		t.Fatal("newRateLimiter(0) should disable limiting by returning nil")
+	}
+	if !rl.wait(nil) {
+		t.Fatal("(*rateLimiter)(nil).wait should always report true")
+	}
+}
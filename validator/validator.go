@@ -1,21 +1,90 @@
 package validator
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/EFForg/starttls-backend/checker"
+	"github.com/EFForg/starttls-backend/dane"
 	"github.com/EFForg/starttls-backend/models"
+	"github.com/EFForg/starttls-backend/mtastscache"
+	"github.com/EFForg/starttls-backend/tlsrpt"
 	"github.com/getsentry/raven-go"
 )
 
+// defaultCacheEvictTTL is how long an MTA-STS cache entry can go unused
+// before the background refresher evicts it.
+const defaultCacheEvictTTL = 30 * 24 * time.Hour
+
+// cacheRefreshMargin controls how far ahead of a policy's ValidEnd the
+// background refresher tries to get a fresh copy.
+const cacheRefreshMargin = time.Hour
+
 // DomainPolicyStore is an interface for any back-end that
 // stores a map of domains to its "policy" (in this case, just the
 // expected hostnames).
 type DomainPolicyStore interface {
 	DomainsToValidate() ([]string, error)
 	GetDomain(string) (models.Domain, error)
+	// RecordOverride persists an operator's decision to silence
+	// SoftMandatory alerts for domain until override.ExpiresAt, so the
+	// reason and who approved it survive a restart and show up in an
+	// audit trail.
+	RecordOverride(domain string, override PolicyOverride) error
+	// GetOverride returns the override on file for domain, if any. A
+	// zero-value PolicyOverride (zero ExpiresAt) means none is recorded.
+	GetOverride(domain string) (PolicyOverride, error)
+}
+
+// PolicySeverity classifies how seriously a domain's failing check should
+// be treated, so a domain known to be flaky doesn't page the same way one
+// that must never regress does.
+type PolicySeverity string
+
+// Policy severities a domain can be tagged with. Domain.Severity is assumed
+// to default to the zero value, which severity() treats as HardMandatory
+// so an un-tagged domain keeps today's always-alert behavior.
+const (
+	// Advisory failures are recorded (TLSRPT, metrics) but never alert.
+	Advisory PolicySeverity = "advisory"
+	// SoftMandatory failures alert unless an unexpired PolicyOverride is
+	// on file for the domain.
+	SoftMandatory PolicySeverity = "soft-mandatory"
+	// HardMandatory failures always alert, regardless of any override.
+	HardMandatory PolicySeverity = "hard-mandatory"
+)
+
+// severity returns domain's configured PolicySeverity, defaulting to
+// HardMandatory. domain.Severity is a plain string rather than a
+// PolicySeverity, since PolicySeverity is declared in this package and
+// models must not import validator, so the conversion is explicit here.
+func severity(domain models.Domain) PolicySeverity {
+	if domain.Severity == "" {
+		return HardMandatory
+	}
+	return PolicySeverity(domain.Severity)
+}
+
+// PolicyOverride is an explicit, time-boxed decision to silence
+// SoftMandatory alerts for a domain, so temporary maintenance doesn't
+// require a code change and still leaves an audit trail of who approved it
+// and why.
+type PolicyOverride struct {
+	Actor     string
+	Reason    string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// active reports whether the override still covers now. A zero ExpiresAt
+// means no override is recorded.
+func (o PolicyOverride) active(now time.Time) bool {
+	return !o.ExpiresAt.IsZero() && now.Before(o.ExpiresAt)
 }
 
 // Called with failure by defaault.
@@ -29,10 +98,56 @@ func reportToSentry(name string, domain string, result checker.DomainResult) {
 		result)
 }
 
-type resultCallback func(string, models.Domain, checker.DomainResult)
+// ValidationEvent is what's handed to every Reporter after a domain's check
+// completes, carrying enough context to decide whether and how to surface
+// it (metrics, alerting, a webhook) without each Reporter re-deriving it.
+type ValidationEvent struct {
+	ValidatorName string
+	Domain        models.Domain
+	Result        CheckResult
+	Success       bool
+	// Severity is the domain's PolicySeverity at the time of this check.
+	// Reporters that alert (e.g. Sentry) should skip Advisory failures
+	// entirely and skip SoftMandatory failures when Overridden is set;
+	// Reporters that only record metrics can ignore both fields.
+	Severity PolicySeverity
+	// Overridden is true for a SoftMandatory failure covered by an active
+	// PolicyOverride.
+	Overridden bool
+	// Duration is how long the check itself took to run.
+	Duration time.Duration
+}
+
+// Reporter is anything Validator can hand a ValidationEvent to after a
+// check completes. Validator doesn't care whether that means alerting,
+// recording a metric, or both; it just calls every configured Reporter.
+type Reporter interface {
+	Report(ctx context.Context, event ValidationEvent) error
+}
+
+// AuthMode records which authentication mechanism(s) a domain's check
+// actually relied on, since a domain can be protected by MTA-STS, DANE,
+// both, or neither (opportunistic STARTTLS only).
+type AuthMode string
+
+// Authentication modes a CheckResult can report.
+const (
+	AuthModeOpportunistic AuthMode = "opportunistic"
+	AuthModeMTASTS        AuthMode = "mta-sts"
+	AuthModeDANE          AuthMode = "dane"
+	AuthModeBoth          AuthMode = "mta-sts+dane"
+)
+
+// CheckResult is a checker.DomainResult together with the authentication
+// mode that produced it, so callers can tell a DANE rollover mistake apart
+// from an MTA-STS policy drift even though both surface as a failure.
+type CheckResult struct {
+	checker.DomainResult
+	AuthMode AuthMode
+}
 
 // CheckPerformer defines a function that performs a security check on a domain.
-type CheckPerformer func(models.Domain) checker.DomainResult
+type CheckPerformer func(models.Domain) CheckResult
 
 // Validator runs checks regularly against domain policies. This structure
 // defines the configurations.
@@ -45,13 +160,63 @@ type Validator struct {
 	// Interval: optional; time at which validator should re-run.
 	// If not set, default interval is 1 day.
 	Interval time.Duration
-	// OnFailure: optional. Called when a particular policy validation fails. Defaults to
-	// a sentry report.
-	OnFailure resultCallback
-	// OnSuccess: optional. Called when a particular policy validation succeeds.
-	OnSuccess resultCallback
+	// Reporters: optional. Every configured Reporter is called with a
+	// ValidationEvent after each domain's check completes. See the
+	// reporter package for built-in Sentry, Prometheus, and webhook
+	// Reporters.
+	Reporters []Reporter
 	// CheckPerformer: performs the check.
 	CheckPerformer CheckPerformer
+	// PolicyCache: optional. On-disk cache of fetched MTA-STS policies,
+	// consulted by GetDBCheck so we don't re-do DNS+HTTPS on every domain
+	// every interval. If nil, no caching is performed.
+	PolicyCache *mtastscache.Cache
+	// CacheEvictTTL: optional. Entries in PolicyCache unused for this long
+	// are evicted by the background refresher. Defaults to 30 days.
+	CacheEvictTTL time.Duration
+	// TLSRPT: optional. Accumulates per-domain success/failure counts and
+	// rotates them into RFC 8460 aggregate reports delivered to the RUAs
+	// each domain publishes. If nil, no reports are generated.
+	TLSRPT *tlsrpt.Accumulator
+	// TLSRPTInterval: optional. How often accumulated counts are rotated
+	// into reports and delivered. Defaults to 24h, per RFC 8460 §5.
+	TLSRPTInterval time.Duration
+	// TLSRPTHTTPSSender and TLSRPTMailtoSender deliver rotated reports to
+	// https: and mailto: RUAs respectively. A nil TLSRPTHTTPSSender
+	// defaults to a tlsrpt.HTTPSSender using http.DefaultClient; a nil
+	// TLSRPTMailtoSender is not defaulted, and mailto: RUAs are skipped.
+	TLSRPTHTTPSSender  tlsrpt.ReportSender
+	TLSRPTMailtoSender tlsrpt.ReportSender
+	// Concurrency: optional. Number of domains checked in parallel by Run.
+	// Defaults to 16.
+	Concurrency int
+	// PerDomainInterval: optional. How often each domain is re-checked.
+	// Unlike Interval, this is scheduled per-domain rather than as one
+	// global sweep, so a large policy list doesn't re-check everything at
+	// once. Defaults to Interval (or its own 24h default).
+	PerDomainInterval time.Duration
+	// Jitter: optional. Domain checks are randomly offset by up to this
+	// much so a large policy list doesn't produce synchronized DNS/TCP
+	// bursts. Defaults to one tenth of PerDomainInterval.
+	Jitter time.Duration
+	// MaxBackoff: optional. Upper bound on the exponential backoff applied
+	// to a domain that keeps failing, so one broken domain doesn't get
+	// retried arbitrarily rarely either. Defaults to 6 * PerDomainInterval.
+	MaxBackoff time.Duration
+	// RateLimit: optional. Maximum domain checks dispatched per second
+	// across all workers, regardless of Concurrency. Zero disables the
+	// limit.
+	RateLimit int
+	// DomainRefreshInterval: optional. How often Run re-reads the domain
+	// list from Store to pick up newly-added or removed domains. Defaults
+	// to PerDomainInterval.
+	DomainRefreshInterval time.Duration
+
+	// defaultCheckPerformerOnce guards the lazy default-CheckPerformer init
+	// in checkPolicy: Run dispatches to v.concurrency() workers that can all
+	// call checkPolicy concurrently, so that init can no longer just check
+	// CheckPerformer == nil and assign it unsynchronized.
+	defaultCheckPerformerOnce sync.Once
 }
 
 // UpdatePolicy is a callback we can provide to GetDBCheck in order to perform a policy
@@ -60,31 +225,132 @@ type UpdatePolicy func(models.Domain) error
 
 // GetDBCheck returns a CheckPerformer that performs an MTASTS check and update if
 // the policy is updated, or performs a regular security check if MTASTS is not supported.
-func GetDBCheck(update UpdatePolicy) CheckPerformer {
+// If cache is non-nil, it is consulted before re-fetching a domain's MTA-STS
+// policy over DNS and HTTPS; see mtastscache for the refresh and backoff rules.
+func GetDBCheck(update UpdatePolicy, cache *mtastscache.Cache) CheckPerformer {
 	c := checker.Checker{Cache: checker.MakeSimpleCache(time.Hour)}
-	return func(domain models.Domain) checker.DomainResult {
-		if domain.MTASTS {
-			result := c.CheckDomain(domain.Name, []string{})
-			if !domain.SamePolicy(result.MTASTSResult) {
-				if update(domain) != nil {
-					reportToSentry("Couldn't update policy in DB", domain.Name, result)
-				}
+	return func(domain models.Domain) CheckResult {
+		if !domain.MTASTS {
+			return withDANE(domain, c.CheckDomain(domain.Name, domain.MXs))
+		}
+		if cache != nil {
+			if entry, ok, stale := cache.Get(domain.Name, defaultCacheRefreshInterval); ok && !stale {
+				return withDANE(domain, entry.Result)
+			}
+		}
+		result := c.CheckDomain(domain.Name, []string{})
+		if cache != nil {
+			cache.Put(entryFromResult(domain.Name, result))
+		}
+		if !domain.SamePolicy(result.MTASTSResult) {
+			if update(domain) != nil {
+				reportToSentry("Couldn't update policy in DB", domain.Name, result)
+			}
+		}
+		return withDANE(domain, result)
+	}
+}
+
+// withDANE layers DANE TLSA validation on top of an MTA-STS/opportunistic
+// result, for MX hosts where domain.DANE is set or TLSA records are
+// published regardless. A DANE mismatch is a security regression (likely a
+// rollover mistake) and fails the combined result even if MTA-STS passed.
+func withDANE(domain models.Domain, result checker.DomainResult) CheckResult {
+	mtastsOK := result.Status == 0
+
+	daneAttempted, daneOK := false, true
+	for _, mx := range domain.MXs {
+		records, err := dane.LookupRecords(mx)
+		switch {
+		case err != nil:
+			if domain.DANE {
+				daneAttempted = true
+				daneOK = false
+				result.Message += fmt.Sprintf("; DANE: could not look up TLSA records for %s: %v", mx, err)
+			}
+			continue
+		case len(records) == 0:
+			if domain.DANE {
+				daneAttempted = true
+				daneOK = false
+				result.Message += fmt.Sprintf("; DANE: no TLSA records published for %s", mx)
 			}
-			return result
+			continue
+		}
+		daneAttempted = true
+		if ok, err := dane.CheckRecords(mx, records); !ok {
+			daneOK = false
+			result.Message += fmt.Sprintf("; DANE: %s failed TLSA validation: %v", mx, err)
 		}
-		return c.CheckDomain(domain.Name, domain.MXs)
 	}
+
+	switch {
+	case !daneAttempted:
+		mode := AuthModeOpportunistic
+		if domain.MTASTS {
+			mode = AuthModeMTASTS
+		}
+		return CheckResult{DomainResult: result, AuthMode: mode}
+	case daneOK && mtastsOK:
+		mode := AuthModeDANE
+		if domain.MTASTS {
+			mode = AuthModeBoth
+		}
+		return CheckResult{DomainResult: result, AuthMode: mode}
+	case daneOK:
+		// DANE passed but the MTA-STS/opportunistic check already failed for
+		// its own reasons; result.Status is already non-zero from that check.
+		return CheckResult{DomainResult: result, AuthMode: AuthModeDANE}
+	default:
+		// DANE itself failed, which fails the domain even if MTA-STS passed
+		// (this is exactly the DANE rollover mistake Validator should catch).
+		// checker.DomainDANEFailure is a real, DANE-specific status, distinct
+		// from checker.DomainBadHostnameFailure, so anything that turns
+		// Status into a human-facing label describes this failure correctly
+		// instead of reporting a hostname mismatch that didn't happen.
+		if result.Status == 0 {
+			result.Status = checker.DomainDANEFailure
+		}
+		return CheckResult{DomainResult: result, AuthMode: AuthModeDANE}
+	}
+}
+
+// defaultCacheRefreshInterval is used in place of a policy's own max_age
+// until we've successfully cached one for a domain.
+const defaultCacheRefreshInterval = time.Hour
+
+// entryFromResult builds the mtastscache.Entry to persist after a fresh
+// MTA-STS fetch for domain.
+func entryFromResult(domain string, result checker.DomainResult) mtastscache.Entry {
+	entry := mtastscache.Entry{
+		Domain: domain,
+		Result: result,
+	}
+	if result.MTASTSResult != nil {
+		entry.Policy = result.MTASTSResult.Policy
+		entry.MaxAge = time.Duration(result.MTASTSResult.MaxAge) * time.Second
+	}
+	// Backoff negative-caches a failed TXT lookup or HTTPS fetch, not a
+	// policy that fetched fine but then failed TLS/cert validation against
+	// an MX host: deriving it from result.Status would instead pull a
+	// correctly-fetched-but-failing domain into the tighter 5-minute
+	// negative-cache cadence, the opposite of what negative caching is for.
+	entry.Backoff = result.MTASTSResult == nil
+	return entry
 }
 
-func (v *Validator) checkPolicy(domain models.Domain) checker.DomainResult {
-	if v.CheckPerformer == nil {
+func (v *Validator) checkPolicy(domain models.Domain) CheckResult {
+	v.defaultCheckPerformerOnce.Do(func() {
+		if v.CheckPerformer != nil {
+			return
+		}
 		c := checker.Checker{
 			Cache: checker.MakeSimpleCache(time.Hour),
 		}
-		v.CheckPerformer = func(domain models.Domain) checker.DomainResult {
-			return c.CheckDomain(domain.Name, domain.MXs)
+		v.CheckPerformer = func(domain models.Domain) CheckResult {
+			return withDANE(domain, c.CheckDomain(domain.Name, domain.MXs))
 		}
-	}
+	})
 	return v.CheckPerformer(domain)
 }
 
@@ -95,47 +361,353 @@ func (v *Validator) interval() time.Duration {
 	return time.Hour * 24
 }
 
-func (v *Validator) policyFailed(name string, domain models.Domain, result checker.DomainResult) {
-	if v.OnFailure != nil {
-		v.OnFailure(name, domain, result)
+// concurrency returns the configured Concurrency, or 16 if unset.
+func (v *Validator) concurrency() int {
+	if v.Concurrency != 0 {
+		return v.Concurrency
 	}
-	reportToSentry(name, domain.Name, result)
+	return 16
 }
 
-func (v *Validator) policyPassed(name string, domain models.Domain, result checker.DomainResult) {
-	if v.OnSuccess != nil {
-		v.OnSuccess(name, domain, result)
+// perDomainInterval returns the configured PerDomainInterval, falling back
+// to Interval and then to interval()'s own 24h default.
+func (v *Validator) perDomainInterval() time.Duration {
+	if v.PerDomainInterval != 0 {
+		return v.PerDomainInterval
 	}
+	return v.interval()
 }
 
-// Run starts the endless loop of validations. The first validation happens after the given
-// Interval. Validation failures induce `policyFailed`, and successes cause `policyPassed`.
-func (v *Validator) Run() {
+// jitter returns the configured Jitter, or one tenth of perDomainInterval
+// if unset.
+func (v *Validator) jitter() time.Duration {
+	if v.Jitter != 0 {
+		return v.Jitter
+	}
+	return v.perDomainInterval() / 10
+}
+
+// maxBackoff returns the configured MaxBackoff, or 6 * perDomainInterval if
+// unset.
+func (v *Validator) maxBackoff() time.Duration {
+	if v.MaxBackoff != 0 {
+		return v.MaxBackoff
+	}
+	return 6 * v.perDomainInterval()
+}
+
+// domainRefreshInterval returns the configured DomainRefreshInterval, or
+// perDomainInterval if unset.
+func (v *Validator) domainRefreshInterval() time.Duration {
+	if v.DomainRefreshInterval != 0 {
+		return v.DomainRefreshInterval
+	}
+	return v.perDomainInterval()
+}
+
+func (v *Validator) policyFailed(ctx context.Context, name string, domain models.Domain, result CheckResult, duration time.Duration) {
+	if v.TLSRPT != nil {
+		v.TLSRPT.RecordFailure(domain.Name, policyType(result), classifyFailure(result))
+	}
+	sev := severity(domain)
+	overridden := sev == SoftMandatory && v.overridden(domain.Name)
+	if overridden {
+		log.Printf("[%s validator] %s: soft-mandatory failure suppressed by active override", v.Name, domain.Name)
+	}
+	v.report(ctx, ValidationEvent{
+		ValidatorName: name,
+		Domain:        domain,
+		Result:        result,
+		Success:       false,
+		Severity:      sev,
+		Overridden:    overridden,
+		Duration:      duration,
+	})
+}
+
+func (v *Validator) policyPassed(ctx context.Context, name string, domain models.Domain, result CheckResult, duration time.Duration) {
+	if v.TLSRPT != nil {
+		v.TLSRPT.RecordSuccess(domain.Name, policyType(result))
+	}
+	v.report(ctx, ValidationEvent{ValidatorName: name, Domain: domain, Result: result, Success: true, Severity: severity(domain), Duration: duration})
+}
+
+// overridden reports whether domain has an active PolicyOverride on file,
+// consulted so a SoftMandatory failure can be silenced without editing code
+// while still leaving an audit trail in the DomainPolicyStore.
+func (v *Validator) overridden(domain string) bool {
+	override, err := v.Store.GetOverride(domain)
+	if err != nil {
+		log.Printf("[%s validator] could not look up override for %s: %v", v.Name, domain, err)
+		return false
+	}
+	return override.active(time.Now())
+}
+
+// report calls every configured Reporter with event, logging (rather than
+// failing the check) any Reporter that errors.
+func (v *Validator) report(ctx context.Context, event ValidationEvent) {
+	for _, r := range v.Reporters {
+		if err := r.Report(ctx, event); err != nil {
+			log.Printf("[%s validator] reporter error: %v", v.Name, err)
+		}
+	}
+}
+
+// policyType reports which kind of policy backed a domain's check, for the
+// TLSRPT report's "policy-type" field, based on the AuthMode the check
+// actually relied on rather than just the domain's MTASTS setting, so a
+// DANE-only domain isn't mislabeled "no-policy-found".
+func policyType(result CheckResult) tlsrpt.PolicyType {
+	switch result.AuthMode {
+	case AuthModeMTASTS, AuthModeBoth:
+		return tlsrpt.PolicyTypeSTS
+	case AuthModeDANE:
+		return tlsrpt.PolicyTypeTLSA
+	}
+	return tlsrpt.PolicyTypeNone
+}
+
+// classifyFailure maps a failed checker.DomainResult onto one of the RFC
+// 8460 result-type strings (plus our sts-* extensions), by inspecting the
+// failure message the checker produced. This is necessarily best-effort:
+// checker doesn't expose a typed failure reason, so we match on the
+// substrings its check messages are known to contain.
+func classifyFailure(result CheckResult) tlsrpt.ResultType {
+	msg := strings.ToLower(result.Message)
+	switch {
+	case strings.Contains(msg, "starttls"):
+		return tlsrpt.ResultStarttlsNotSupported
+	case strings.Contains(msg, "hostname") || strings.Contains(msg, "host mismatch"):
+		return tlsrpt.ResultCertificateHostMismatch
+	case strings.Contains(msg, "expired"):
+		return tlsrpt.ResultCertificateExpired
+	case strings.Contains(msg, "trust"):
+		return tlsrpt.ResultCertificateNotTrusted
+	case strings.Contains(msg, "mta-sts") && strings.Contains(msg, "fetch"):
+		return tlsrpt.ResultSTSPolicyFetchError
+	case strings.Contains(msg, "mta-sts") && strings.Contains(msg, "invalid"):
+		return tlsrpt.ResultSTSPolicyInvalid
+	case strings.Contains(msg, "webpki"):
+		return tlsrpt.ResultSTSWebPKIInvalid
+	default:
+		return tlsrpt.ResultValidationFailure
+	}
+}
+
+// cacheEvictTTL returns the configured CacheEvictTTL, or defaultCacheEvictTTL
+// if unset.
+func (v *Validator) cacheEvictTTL() time.Duration {
+	if v.CacheEvictTTL != 0 {
+		return v.CacheEvictTTL
+	}
+	return defaultCacheEvictTTL
+}
+
+// runCacheMaintenance periodically refreshes cache entries approaching
+// expiry and evicts ones that haven't been used in a while, so PolicyCache
+// doesn't grow without bound and doesn't serve stale policies past
+// ValidEnd. It's started as a background goroutine from Run and returns
+// once ctx is done.
+func (v *Validator) runCacheMaintenance(ctx context.Context) {
+	ticker := time.NewTicker(v.interval())
+	defer ticker.Stop()
 	for {
-		<-time.After(v.interval())
-		log.Printf("[%s validator] starting regular validation", v.Name)
-		domains, err := v.Store.DomainsToValidate()
-		if err != nil {
-			log.Printf("[%s validator] Could not retrieve domains: %v", v.Name, err)
-			continue
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
 		}
-		for _, domain := range domains {
+		for _, domain := range v.PolicyCache.ApproachingExpiry(cacheRefreshMargin) {
 			domainData, err := v.Store.GetDomain(domain)
 			if err != nil {
-				log.Printf("[%s validator] Could not retrieve policy for domain %s: %v", v.Name, domain, err)
+				log.Printf("[%s validator] cache refresh: could not retrieve domain %s: %v", v.Name, domain, err)
 				continue
 			}
-			result := v.checkPolicy(domainData)
-			if result.Status != 0 {
-				log.Printf("[%s validator] %s failed; sending report", v.Name, domain)
-				v.policyFailed(v.Name, domainData, result)
-			} else {
-				v.policyPassed(v.Name, domainData, result)
+			v.checkPolicy(domainData)
+		}
+		removed, err := v.PolicyCache.Evict(v.cacheEvictTTL())
+		if removed > 0 {
+			log.Printf("[%s validator] evicted %d stale cache entries", v.Name, removed)
+		}
+		if err != nil {
+			log.Printf("[%s validator] saving cache after eviction: %v", v.Name, err)
+		}
+	}
+}
+
+// tlsrptInterval returns the configured TLSRPTInterval, or 24h if unset.
+func (v *Validator) tlsrptInterval() time.Duration {
+	if v.TLSRPTInterval != 0 {
+		return v.TLSRPTInterval
+	}
+	return 24 * time.Hour
+}
+
+// runTLSRPT rotates v.TLSRPT on a timer and delivers each resulting report
+// to the RUAs its domain publishes. It's started as a background goroutine
+// from Run and returns once ctx is done.
+func (v *Validator) runTLSRPT(ctx context.Context) {
+	httpsSender := v.TLSRPTHTTPSSender
+	if httpsSender == nil {
+		httpsSender = tlsrpt.HTTPSSender{Client: http.DefaultClient}
+	}
+	ticker := time.NewTicker(v.tlsrptInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for domain, report := range v.TLSRPT.Rotate() {
+			ruas, err := tlsrpt.LookupRUAs(domain)
+			if err != nil {
+				log.Printf("[%s validator] tlsrpt: %v", v.Name, err)
+				continue
+			}
+			if err := tlsrpt.Deliver(ctx, domain, ruas, report, httpsSender, v.TLSRPTMailtoSender); err != nil {
+				log.Printf("[%s validator] tlsrpt: delivering report for %s: %v", v.Name, domain, err)
 			}
 		}
 	}
 }
 
+// checkDomainByName retrieves domain's policy from Store and runs
+// checkPolicy against it, logging and routing the result the same way the
+// old serial loop did. It reports whether the check succeeded, so the
+// scheduler knows whether to back off.
+func (v *Validator) checkDomainByName(ctx context.Context, domain string) bool {
+	domainData, err := v.Store.GetDomain(domain)
+	if err != nil {
+		log.Printf("[%s validator] Could not retrieve policy for domain %s: %v", v.Name, domain, err)
+		return false
+	}
+	start := time.Now()
+	result := v.checkPolicy(domainData)
+	duration := time.Since(start)
+	if result.Status != 0 {
+		log.Printf("[%s validator] %s failed; sending report", v.Name, domain)
+		v.policyFailed(ctx, v.Name, domainData, result, duration)
+		return false
+	}
+	v.policyPassed(ctx, v.Name, domainData, result, duration)
+	return true
+}
+
+// jobResult is what a worker reports back to the dispatcher after checking
+// a domain, so it can be rescheduled appropriately.
+type jobResult struct {
+	job     *domainJob
+	success bool
+}
+
+// Run starts a worker-pool scheduler: each domain gets its own next-check
+// time (instead of one global sweep every Interval), spread out by Jitter
+// so a large policy list doesn't produce synchronized DNS/TCP bursts, and
+// dispatched to Concurrency workers that respect RateLimit. A domain that
+// fails gets exponential backoff, bounded by MaxBackoff, before its next
+// attempt. Validation failures induce `policyFailed`, and successes cause
+// `policyPassed`. If PolicyCache is set, a background goroutine refreshes
+// entries approaching expiry and evicts ones that have gone unused. If
+// TLSRPT is set, a background goroutine rotates and delivers aggregate
+// reports. Run blocks until ctx is done, then returns ctx.Err().
+func (v *Validator) Run(ctx context.Context) error {
+	if v.PolicyCache != nil {
+		go v.runCacheMaintenance(ctx)
+	}
+	if v.TLSRPT != nil {
+		go v.runTLSRPT(ctx)
+	}
+
+	limiter := newRateLimiter(v.RateLimit)
+	sched := newScheduler(v.jitter())
+	jobs := make(chan *domainJob)
+	results := make(chan jobResult)
+
+	for i := 0; i < v.concurrency(); i++ {
+		go func() {
+			for job := range jobs {
+				success := v.checkDomainByName(ctx, job.name)
+				select {
+				case results <- jobResult{job: job, success: success}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+	defer close(jobs)
+
+	if err := v.refreshDomains(sched); err != nil {
+		log.Printf("[%s validator] Could not retrieve domains: %v", v.Name, err)
+	}
+
+	refreshTicker := time.NewTicker(v.domainRefreshInterval())
+	defer refreshTicker.Stop()
+	wakeTimer := time.NewTimer(sched.minWait)
+	defer wakeTimer.Stop()
+
+	perDomainInterval, maxBackoff := v.perDomainInterval(), v.maxBackoff()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-refreshTicker.C:
+			if err := v.refreshDomains(sched); err != nil {
+				log.Printf("[%s validator] Could not retrieve domains: %v", v.Name, err)
+			}
+		case r := <-results:
+			sched.reschedule(r.job, r.success, perDomainInterval, maxBackoff)
+		case <-wakeTimer.C:
+		}
+
+		for {
+			_, wait, ok := sched.nextDue()
+			if !ok {
+				wakeTimer.Reset(sched.minWait)
+				break
+			}
+			if wait > 0 {
+				wakeTimer.Reset(wait)
+				break
+			}
+			if !limiter.wait(ctx.Done()) {
+				return ctx.Err()
+			}
+			job := sched.pop()
+			// Keep draining results while trying to dispatch job: with more
+			// domains due at once than Concurrency, every worker can be
+			// blocked trying to deposit a finished result here, and unless
+			// this loop keeps reading results too, jobs <- job would block
+			// forever with no worker free to receive it.
+			for dispatched := false; !dispatched; {
+				select {
+				case jobs <- job:
+					dispatched = true
+				case r := <-results:
+					sched.reschedule(r.job, r.success, perDomainInterval, maxBackoff)
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+		}
+	}
+}
+
+// refreshDomains adds any domain Store knows about that the scheduler
+// isn't already tracking.
+func (v *Validator) refreshDomains(sched *scheduler) error {
+	domains, err := v.Store.DomainsToValidate()
+	if err != nil {
+		return err
+	}
+	for _, domain := range domains {
+		sched.ensure(domain)
+	}
+	return nil
+}
+
 // ValidateRegularly regularly runs checker.CheckDomain against a Domain-
 // Hostname map. Interval specifies the interval to wait between each run.
 // Failures are reported to Sentry.
@@ -145,5 +717,5 @@ func ValidateRegularly(name string, store DomainPolicyStore, interval time.Durat
 		Store:    store,
 		Interval: interval,
 	}
-	v.Run()
+	v.Run(context.Background())
 }